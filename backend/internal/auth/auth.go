@@ -2,12 +2,10 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
-	"fmt"
-	"log"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/alexedwards/argon2id"
@@ -28,60 +26,109 @@ func CheckPasswordHash(pass, hash string) (bool, error) {
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
-	tokenString := strings.Split(headers.Get("Authorization"), " ")[1]
-	if tokenString == "" {
-		return "", errors.New("No token provided")
-	}
-	return tokenString, nil
+	return GetAuthorization(headers, SchemeBearer)
 }
 
 func GetApiKey(headers http.Header) (string, error) {
-	apiKey := strings.Split(headers.Get("Authorization"), " ")[2]
-	if apiKey == "" {
-		return "", fmt.Errorf("No Api Key")
-	}
-	return apiKey, nil
+	return GetAuthorization(headers, SchemeApiKey)
 }
 
-func MakeJWT(userID uuid.UUID, tokenSecret string) (string, error) {
-	log.Println(tokenSecret)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		Subject:   userID.String(),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(60 * 24 * time.Hour)),
-	})
+const (
+	TokenIssuer   = "chirpy"
+	TokenAudience = "chirpy-api"
+)
 
-	signedToken, err := token.SignedString([]byte(tokenSecret))
-	if err != nil {
-		return "", err
-	}
-	return signedToken, nil
+// AccessClaims are the parts of a verified access token the API uses.
+// SessionID is the session the token was issued for; it is the zero UUID
+// for tokens that predate the sid claim.
+type AccessClaims struct {
+	UserID    uuid.UUID
+	TokenID   string
+	SessionID uuid.UUID
+	ExpiresAt time.Time
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+type accessTokenClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid,omitempty"`
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
-		return []byte(tokenSecret), nil
+func MakeJWT(userID, sessionID uuid.UUID, keys *KeySet, expiresIn time.Duration) (string, error) {
+	return keys.sign(accessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{TokenAudience},
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+		SessionID: sessionID.String(),
 	})
+}
+
+// ParseAccessToken only accepts RS256 or EdDSA tokens signed by a
+// non-retired key, issued by Chirpy for the API audience, that carry a
+// subject, a jti and an expiry.
+func ParseAccessToken(tokenString string, keys *KeySet) (AccessClaims, error) {
+	claims := &accessTokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, keys.verificationKey,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithAudience(TokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil {
-		return uuid.UUID{}, err
+		return AccessClaims{}, err
 	}
-	strID, err := token.Claims.GetSubject()
+	id, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return uuid.UUID{}, err
+		return AccessClaims{}, err
+	}
+	if claims.ID == "" {
+		return AccessClaims{}, errors.New("token has no jti")
+	}
+	sessionID := uuid.Nil
+	if claims.SessionID != "" {
+		sessionID, err = uuid.Parse(claims.SessionID)
+		if err != nil {
+			return AccessClaims{}, err
+		}
 	}
-	id, err := uuid.Parse(strID)
+	return AccessClaims{
+		UserID:    id,
+		TokenID:   claims.ID,
+		SessionID: sessionID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func ValidateJWT(tokenString string, keys *KeySet) (uuid.UUID, error) {
+	claims, err := ParseAccessToken(tokenString, keys)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
-	return id, nil
+	return claims.UserID, nil
 }
 
+// MakeRefreshToken returns a random token for the client. Only its HashToken
+// is stored.
 func MakeRefreshToken() (string, error) {
-	data := make([]byte, 16)
-	rand.Read(data)
-	test := hex.EncodeToString(data)
-	log.Println(test)
+	return MakeSecretToken()
+}
+
+// MakeSecretToken returns a random token for links sent by email. Only its
+// HashToken should be stored.
+func MakeSecretToken() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
 	return hex.EncodeToString(data), nil
 }
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// DenyList holds the jti of access tokens that were killed before they
+// expired. Entries are dropped once the token would have expired anyway, so
+// the list stays small.
+type DenyList struct {
+	mu     sync.RWMutex
+	denied map[string]time.Time
+}
+
+func NewDenyList() *DenyList {
+	return &DenyList{denied: map[string]time.Time{}}
+}
+
+func (d *DenyList) Deny(tokenID string, expiresAt time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range d.denied {
+		if now.After(exp) {
+			delete(d.denied, id)
+		}
+	}
+	if now.Before(expiresAt) {
+		d.denied[tokenID] = expiresAt
+	}
+}
+
+func (d *DenyList) IsDenied(tokenID string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	_, ok := d.denied[tokenID]
+	return ok
+}
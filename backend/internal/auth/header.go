@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	SchemeBearer = "Bearer"
+	SchemeApiKey = "ApiKey"
+)
+
+var (
+	ErrNoAuthHeader        = errors.New("no authorization header")
+	ErrMalformedAuthHeader = errors.New("malformed authorization header")
+	ErrMissingCredentials  = errors.New("authorization header has no credentials")
+)
+
+// SchemeError is returned when the header uses a different scheme than the
+// one the caller asked for.
+type SchemeError struct {
+	Want string
+	Got  string
+}
+
+func (e *SchemeError) Error() string {
+	return fmt.Sprintf("expected %s authorization, got %s", e.Want, e.Got)
+}
+
+// Credentials is a parsed "Authorization: <scheme> <value>" header.
+type Credentials struct {
+	Scheme string
+	Value  string
+}
+
+// Is compares schemes case-insensitively, as RFC 9110 requires.
+func (c Credentials) Is(scheme string) bool {
+	return strings.EqualFold(c.Scheme, scheme)
+}
+
+// ParseAuthorization splits a header value into scheme and credentials. Any
+// amount of spaces or tabs may surround and separate the two, but the
+// credentials themselves must be a single non-empty token.
+func ParseAuthorization(header string) (Credentials, error) {
+	header = strings.Trim(header, " \t")
+	if header == "" {
+		return Credentials{}, ErrNoAuthHeader
+	}
+
+	scheme, value := header, ""
+	if i := strings.IndexAny(header, " \t"); i >= 0 {
+		scheme, value = header[:i], strings.Trim(header[i+1:], " \t")
+	}
+
+	if !isToken(scheme) {
+		return Credentials{}, ErrMalformedAuthHeader
+	}
+	if value == "" {
+		return Credentials{}, ErrMissingCredentials
+	}
+	if !isCredential(value) {
+		return Credentials{}, ErrMalformedAuthHeader
+	}
+	return Credentials{Scheme: scheme, Value: value}, nil
+}
+
+// GetAuthorization returns the credentials of the Authorization header if it
+// uses the given scheme. Several Authorization headers are rejected instead
+// of guessing which one counts.
+func GetAuthorization(headers http.Header, scheme string) (string, error) {
+	values := headers.Values("Authorization")
+	switch len(values) {
+	case 0:
+		return "", ErrNoAuthHeader
+	case 1:
+	default:
+		return "", ErrMalformedAuthHeader
+	}
+
+	creds, err := ParseAuthorization(values[0])
+	if err != nil {
+		return "", err
+	}
+	if !creds.Is(scheme) {
+		return "", &SchemeError{Want: scheme, Got: creds.Scheme}
+	}
+	return creds.Value, nil
+}
+
+// isToken reports whether s is an RFC 9110 token.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isCredential accepts any visible ASCII, which covers token68 values like
+// JWTs as well as opaque keys.
+func isCredential(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] <= ' ' || s[i] >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseAuthorization(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    Credentials
+		wantErr error
+	}{
+		{"bearer", "Bearer abc.def.ghi", Credentials{"Bearer", "abc.def.ghi"}, nil},
+		{"api key", "ApiKey f271c81ff7084ee5b99a5091b42d486e", Credentials{"ApiKey", "f271c81ff7084ee5b99a5091b42d486e"}, nil},
+		{"unknown scheme", "Basic dXNlcjpwYXNz", Credentials{"Basic", "dXNlcjpwYXNz"}, nil},
+		{"token68 padding", "Bearer abc==", Credentials{"Bearer", "abc=="}, nil},
+		{"lowercase scheme", "bearer abc", Credentials{"bearer", "abc"}, nil},
+		{"extra spaces between", "Bearer    abc", Credentials{"Bearer", "abc"}, nil},
+		{"tab between", "Bearer\tabc", Credentials{"Bearer", "abc"}, nil},
+		{"surrounding whitespace", " \tBearer abc \t", Credentials{"Bearer", "abc"}, nil},
+		{"empty", "", Credentials{}, ErrNoAuthHeader},
+		{"only whitespace", "   \t ", Credentials{}, ErrNoAuthHeader},
+		{"scheme only", "Bearer", Credentials{}, ErrMissingCredentials},
+		{"scheme and spaces", "Bearer    ", Credentials{}, ErrMissingCredentials},
+		{"value only", " abc.def.ghi", Credentials{}, ErrMissingCredentials},
+		{"two values", "Bearer abc def", Credentials{}, ErrMalformedAuthHeader},
+		{"old ApiKey layout", "ApiKey  key extra", Credentials{}, ErrMalformedAuthHeader},
+		{"bad scheme char", "Bear:er abc", Credentials{}, ErrMalformedAuthHeader},
+		{"quoted scheme", `"Bearer" abc`, Credentials{}, ErrMalformedAuthHeader},
+		{"newline in value", "Bearer abc\ndef", Credentials{}, ErrMalformedAuthHeader},
+		{"NUL in value", "Bearer abc\x00", Credentials{}, ErrMalformedAuthHeader},
+		{"non-ASCII value", "Bearer tökén", Credentials{}, ErrMalformedAuthHeader},
+		{"non-ASCII scheme", "Bëarer abc", Credentials{}, ErrMalformedAuthHeader},
+		{"huge value", "Bearer " + strings.Repeat("a", 1<<16), Credentials{"Bearer", strings.Repeat("a", 1<<16)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAuthorization(tt.header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseAuthorization(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAuthorization(%q) = %+v, want %+v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    []string
+		scheme     string
+		want       string
+		wantErr    error
+		wantScheme bool
+	}{
+		{"bearer", []string{"Bearer abc"}, SchemeBearer, "abc", nil, false},
+		{"case-insensitive scheme", []string{"BEARER abc"}, SchemeBearer, "abc", nil, false},
+		{"api key", []string{"apikey secret"}, SchemeApiKey, "secret", nil, false},
+		{"no header", nil, SchemeBearer, "", ErrNoAuthHeader, false},
+		{"empty header", []string{""}, SchemeBearer, "", ErrNoAuthHeader, false},
+		{"wrong scheme", []string{"ApiKey secret"}, SchemeBearer, "", nil, true},
+		{"scheme prefix only", []string{"Bearerabc"}, SchemeBearer, "", ErrMissingCredentials, false},
+		{"several headers", []string{"Bearer abc", "Bearer def"}, SchemeBearer, "", ErrMalformedAuthHeader, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := http.Header{}
+			for _, v := range tt.headers {
+				headers.Add("Authorization", v)
+			}
+
+			got, err := GetAuthorization(headers, tt.scheme)
+			var schemeErr *SchemeError
+			if tt.wantScheme {
+				if !errors.As(err, &schemeErr) {
+					t.Fatalf("GetAuthorization() error = %v, want a *SchemeError", err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetAuthorization() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetAuthorization() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBearerTokenAndApiKeyDoNotPanic(t *testing.T) {
+	inputs := []string{"", " ", "Bearer", "ApiKey", "Bearer ", "ApiKey ", "a b c d", "\x00", "\t\t"}
+	for _, input := range inputs {
+		headers := http.Header{}
+		headers.Set("Authorization", input)
+		GetBearerToken(headers)
+		GetApiKey(headers)
+	}
+}
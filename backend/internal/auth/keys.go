@@ -0,0 +1,417 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const (
+	// JWKSMaxAge is how long verifiers may cache the JWKS.
+	JWKSMaxAge = 5 * time.Minute
+
+	// keyPublishDelay is how long a new key is only published before it
+	// signs anything, so verifiers holding a cached JWKS have refetched it
+	// by the time the first token signed with it arrives.
+	keyPublishDelay = 2 * JWKSMaxAge
+
+	// keyReloadInterval limits how often an unknown kid makes us look at
+	// the key directory again.
+	keyReloadInterval = 10 * time.Second
+
+	kidTimeFormat = "20060102T150405Z"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrRetiredKey = errors.New("signing key has been retired")
+)
+
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	private   crypto.Signer
+}
+
+// activatesAt is when the key starts signing tokens.
+func (k *SigningKey) activatesAt() time.Time {
+	return k.CreatedAt.Add(keyPublishDelay)
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// KeySet holds the keys used to sign and verify access tokens. Keys live in a
+// directory as PKCS#8 PEM files named <kid>.pem, where the kid starts with
+// the UTC time the key was created. A new key is published right away but
+// only starts signing after keyPublishDelay; once a key has been replaced it
+// keeps verifying tokens for the grace period and is then retired and
+// removed from the directory.
+//
+// Several instances may share the directory. Each one rereads it regularly
+// and whenever it sees an unknown kid, and since keys are ordered by the
+// time in their kid they all agree on which key signs and which are retired.
+type KeySet struct {
+	mu       sync.RWMutex
+	dir      string
+	alg      string
+	grace    time.Duration
+	keys     []*SigningKey
+	loadedAt time.Time
+}
+
+// LoadKeySet reads every key in dir, creating the directory and a first key
+// of the given algorithm when there is none yet.
+func LoadKeySet(dir, alg string, grace time.Duration) (*KeySet, error) {
+	if alg != AlgRS256 && alg != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{dir: dir, alg: alg, grace: grace}
+	if err := ks.reload(); err != nil {
+		return nil, err
+	}
+	if len(ks.keys) == 0 {
+		if err := ks.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	ks.prune()
+	return ks, nil
+}
+
+func sortKeys(keys []*SigningKey) {
+	slices.SortFunc(keys, func(a, b *SigningKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// reload brings the keys in line with the directory, picking up keys other
+// instances created and dropping the ones they removed.
+func (ks *KeySet) reload() error {
+	paths, err := filepath.Glob(filepath.Join(ks.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	ks.mu.RLock()
+	known := make(map[string]*SigningKey, len(ks.keys))
+	for _, key := range ks.keys {
+		known[key.ID] = key
+	}
+	ks.mu.RUnlock()
+
+	keys := make([]*SigningKey, 0, len(paths))
+	for _, path := range paths {
+		if key, ok := known[strings.TrimSuffix(filepath.Base(path), ".pem")]; ok {
+			keys = append(keys, key)
+			continue
+		}
+		key, err := readKeyFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 && len(known) > 0 {
+		return fmt.Errorf("no keys left in %s", ks.dir)
+	}
+	sortKeys(keys)
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.loadedAt = time.Now()
+	ks.mu.Unlock()
+	return nil
+}
+
+func kidTime(kid string) (time.Time, error) {
+	stamp, _, _ := strings.Cut(kid, "-")
+	created, err := time.Parse(kidTimeFormat, stamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("key id %q doesn't start with a %s timestamp", kid, kidTimeFormat)
+	}
+	return created, nil
+}
+
+func readKeyFile(path string) (*SigningKey, error) {
+	kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+	created, err := kidTime(kid)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{
+		ID:        kid,
+		CreatedAt: created,
+	}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = AlgRS256
+		key.private = private
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgEdDSA
+		key.private = private
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return key, nil
+}
+
+func generateKey(alg string) (crypto.Signer, error) {
+	if alg == AlgRS256 {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	return private, err
+}
+
+// Rotate generates a new key and writes it to the key directory. It is
+// published at once and takes over signing after keyPublishDelay; tokens
+// signed with the previous key stay valid for the grace period after that.
+func (ks *KeySet) Rotate() error {
+	private, err := generateKey(ks.alg)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	kid := now.Format(kidTimeFormat) + "-" + hex.EncodeToString(suffix)
+
+	path := filepath.Join(ks.dir, kid+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = append(ks.keys, &SigningKey{
+		ID:        kid,
+		Algorithm: ks.alg,
+		CreatedAt: now,
+		private:   private,
+	})
+	sortKeys(ks.keys)
+	ks.mu.Unlock()
+
+	ks.prune()
+	return nil
+}
+
+// RotateEvery keeps the key set in sync with the directory, cleans up
+// retired keys and, when interval is positive, generates a new key whenever
+// the newest one gets older than interval. It runs until ctx is done.
+func (ks *KeySet) RotateEvery(ctx context.Context, interval time.Duration) {
+	for {
+		if err := ks.reload(); err != nil {
+			log.Printf("Couldn't reload signing keys: %v", err)
+		}
+		ks.prune()
+
+		// Wake up often enough to see keys other instances created
+		// before they start signing.
+		wait := keyPublishDelay / 2
+		if interval > 0 {
+			if due := time.Until(ks.newestKey().CreatedAt.Add(interval)); due <= 0 {
+				if err := ks.Rotate(); err != nil {
+					log.Printf("Couldn't rotate signing key: %v", err)
+				} else {
+					key := ks.newestKey()
+					log.Printf("Generated signing key %s, it signs from %v", key.ID, key.activatesAt())
+					continue
+				}
+			} else if due < wait {
+				wait = due
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (ks *KeySet) newestKey() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[len(ks.keys)-1]
+}
+
+// signingIndexLocked returns the newest key that has been published long
+// enough. Before any has, e.g. right after the very first key was created,
+// the oldest key signs. The caller must hold ks.mu.
+func (ks *KeySet) signingIndexLocked(now time.Time) int {
+	index := 0
+	for i, key := range ks.keys {
+		if !now.Before(key.activatesAt()) {
+			index = i
+		}
+	}
+	return index
+}
+
+func (ks *KeySet) signingKey() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[ks.signingIndexLocked(time.Now())]
+}
+
+// retiredLocked reports whether the key at index i was replaced as signing
+// key more than the grace period ago. The caller must hold ks.mu.
+func (ks *KeySet) retiredLocked(i int, now time.Time) bool {
+	if i >= ks.signingIndexLocked(now) {
+		return false
+	}
+	return now.After(ks.keys[i+1].activatesAt().Add(ks.grace))
+}
+
+// prune forgets retired keys and deletes their files.
+func (ks *KeySet) prune() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	now := time.Now()
+	kept := ks.keys[:0:0]
+	for i, key := range ks.keys {
+		if !ks.retiredLocked(i, now) {
+			kept = append(kept, key)
+			continue
+		}
+		path := filepath.Join(ks.dir, key.ID+".pem")
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Couldn't remove retired key %s: %v", key.ID, err)
+		}
+	}
+	ks.keys = kept
+}
+
+// activeKeys returns the keys that may still verify tokens, including ones
+// that are published but don't sign yet.
+func (ks *KeySet) activeKeys() []*SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+	active := []*SigningKey{}
+	for i, key := range ks.keys {
+		if !ks.retiredLocked(i, now) {
+			active = append(active, key)
+		}
+	}
+	return active
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	key := ks.signingKey()
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+func (ks *KeySet) lookup(kid string) (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	for i, key := range ks.keys {
+		if key.ID != kid {
+			continue
+		}
+		if ks.retiredLocked(i, time.Now()) {
+			return nil, ErrRetiredKey
+		}
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// verificationKey picks the public key named by the token's kid. The key must
+// not be retired and must match the algorithm in the header. An unknown kid
+// may come from a key another instance just created, so the directory is
+// reread, at most once per keyReloadInterval.
+func (ks *KeySet) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKey
+	}
+
+	key, err := ks.lookup(kid)
+	if errors.Is(err, ErrUnknownKey) {
+		ks.mu.RLock()
+		due := time.Since(ks.loadedAt) > keyReloadInterval
+		ks.mu.RUnlock()
+		if due {
+			if err := ks.reload(); err != nil {
+				log.Printf("Couldn't reload signing keys: %v", err)
+			}
+			key, err = ks.lookup(kid)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("key %s is not an %s key", kid, token.Method.Alg())
+	}
+	return key.private.Public(), nil
+}
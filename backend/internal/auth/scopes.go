@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	ScopeChirpsRead   = "chirps:read"
+	ScopeChirpsWrite  = "chirps:write"
+	ScopeProfileWrite = "profile:write"
+)
+
+// Scopes lists every scope a personal access token can be granted.
+var Scopes = []string{ScopeChirpsRead, ScopeChirpsWrite, ScopeProfileWrite}
+
+// personalAccessTokenPrefix tells personal access tokens apart from JWTs and
+// makes leaked tokens easy to grep for.
+const personalAccessTokenPrefix = "chirpy_pat_"
+
+func MakePersonalAccessToken() (string, error) {
+	secret, err := MakeSecretToken()
+	if err != nil {
+		return "", err
+	}
+	return personalAccessTokenPrefix + secret, nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
+
+// ParseScopes checks that every requested scope exists and returns them
+// deduplicated and sorted.
+func ParseScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("at least one scope is required, choose from %s", strings.Join(Scopes, ", "))
+	}
+	scopes := []string{}
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q, choose from %s", scope, strings.Join(Scopes, ", "))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes)
+	return scopes, nil
+}
+
+// HasScopes reports whether granted covers every required scope.
+func HasScopes(granted, required []string) bool {
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const emailVerificationAudience = "chirpy-email-verification"
+
+type EmailVerificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// verificationKey derives a separate signing key so a verification token can
+// never pass ValidateJWT as an access token.
+func verificationKey(tokenSecret string) []byte {
+	sum := sha256.Sum256([]byte("email-verification:" + tokenSecret))
+	return sum[:]
+}
+
+// MakeEmailVerificationToken signs a token proving that whoever holds it
+// received mail at email. tokenID is stored so the token can be used once.
+func MakeEmailVerificationToken(userID, tokenID uuid.UUID, email, tokenSecret string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, EmailVerificationClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "chirpy",
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			ID:        tokenID.String(),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	})
+	return token.SignedString(verificationKey(tokenSecret))
+}
+
+func ValidateEmailVerificationToken(tokenString, tokenSecret string) (userID, tokenID uuid.UUID, email string, err error) {
+	claims := &EmailVerificationClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return verificationKey(tokenSecret), nil
+	}, jwt.WithAudience(emailVerificationAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return uuid.UUID{}, uuid.UUID{}, "", err
+	}
+	userID, err = uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.UUID{}, uuid.UUID{}, "", err
+	}
+	tokenID, err = uuid.Parse(claims.ID)
+	if err != nil {
+		return uuid.UUID{}, uuid.UUID{}, "", err
+	}
+	if claims.Email == "" {
+		return uuid.UUID{}, uuid.UUID{}, "", errors.New("verification token has no email")
+	}
+	return userID, tokenID, claims.Email, nil
+}
@@ -0,0 +1,31 @@
+package chirps
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxTagLength = 50
+
+// A hashtag has to start a word, so "a#b" and HTML entities like "&#39;" are
+// not picked up.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#])#([\p{L}\p{N}_]+)`)
+
+// Hashtags returns the lowercased tags in body in the order they first
+// appear. Purely numeric tags such as "#1" and tags longer than MaxTagLength
+// are ignored.
+func Hashtags(body string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		tag := strings.ToLower(match[1])
+		if seen[tag] || utf8.RuneCountInString(tag) > MaxTagLength || !strings.ContainsFunc(tag, unicode.IsLetter) {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -0,0 +1,26 @@
+package chirps
+
+import (
+	"regexp"
+
+	"github.com/ShkolZ/chirpy/backend/internal/handles"
+)
+
+// A mention has to start a word, so email addresses are not picked up.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}_]+)`)
+
+// Mentions returns the normalized handles mentioned in body in the order
+// they first appear. Tokens that can't be a handle are ignored.
+func Mentions(body string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := handles.Normalize(match[1])
+		if seen[handle] || !handles.Valid(handle) {
+			continue
+		}
+		seen[handle] = true
+		mentions = append(mentions, handle)
+	}
+	return mentions
+}
@@ -0,0 +1,78 @@
+package chirps
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ShkolZ/chirpy/backend/internal/moderation"
+)
+
+const MaxLength = 140
+
+type ValidationError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type Validated struct {
+	Body string
+	// Flags holds the words the filter wants a moderator to look at. The
+	// chirp is still allowed.
+	Flags    []string
+	Tags     []string
+	Mentions []string
+}
+
+// Validate runs every check a chirp body has to pass before it is stored and
+// returns the body the filter left behind. Failures are always
+// ValidationErrors.
+func Validate(body string, filter moderation.Filter) (Validated, error) {
+	errs := ValidationErrors{}
+	if strings.TrimSpace(body) == "" {
+		errs = append(errs, ValidationError{
+			Field:   "body",
+			Code:    "empty",
+			Message: "Chirp must not be empty",
+		})
+	}
+	if utf8.RuneCountInString(body) > MaxLength {
+		errs = append(errs, ValidationError{
+			Field:   "body",
+			Code:    "too_long",
+			Message: "Chirp is too long",
+		})
+	}
+
+	res := filter.Check(body)
+	if res.Matched() && res.Action == moderation.ActionReject {
+		errs = append(errs, ValidationError{
+			Field:   "body",
+			Code:    "profanity",
+			Message: "Chirp contains words that are not allowed",
+		})
+	}
+	if len(errs) > 0 {
+		return Validated{}, errs
+	}
+
+	validated := Validated{
+		Body:     res.Text,
+		Tags:     Hashtags(res.Text),
+		Mentions: Mentions(res.Text),
+	}
+	if res.Matched() && res.Action == moderation.ActionFlag {
+		validated.Flags = res.Matches
+	}
+	return validated, nil
+}
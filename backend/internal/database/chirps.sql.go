@@ -7,28 +7,48 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const chirpHasReplies = `-- name: ChirpHasReplies :one
+SELECT EXISTS (
+    SELECT 1 FROM chirps
+    WHERE parent_id = $1::uuid
+)
+`
+
+func (q *Queries) ChirpHasReplies(ctx context.Context, chirpID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, chirpHasReplies, chirpID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps(id, body, created_at, updated_at, user_id )
+INSERT INTO chirps(id, body, created_at, updated_at, user_id, parent_id, quote_of)
 VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
-) RETURNING id, body, created_at, updated_at, user_id
+    $5,
+    $6,
+    $7
+) RETURNING id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of
 `
 
 type CreateChirpParams struct {
-	ID        uuid.UUID `json:"id"`
-	Body      string    `json:"body"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID        uuid.UUID     `json:"id"`
+	Body      string        `json:"body"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	UserID    uuid.UUID     `json:"user_id"`
+	ParentID  uuid.NullUUID `json:"parent_id"`
+	QuoteOf   uuid.NullUUID `json:"quote_of"`
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
@@ -38,6 +58,8 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.UserID,
+		arg.ParentID,
+		arg.QuoteOf,
 	)
 	var i Chirp
 	err := row.Scan(
@@ -46,11 +68,60 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
+		&i.BodyTsv,
+		&i.ParentID,
+		&i.DeletedAt,
+		&i.RepostOf,
+		&i.QuoteOf,
 	)
 	return i, err
 }
 
-const deleteChirpById = `-- name: DeleteChirpById :exec
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps(id, body, created_at, updated_at, user_id, repost_of)
+VALUES (
+    $1,
+    '',
+    $2,
+    $3,
+    $4,
+    $5
+) RETURNING id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of
+`
+
+type CreateRechirpParams struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	UserID    uuid.UUID     `json:"user_id"`
+	RepostOf  uuid.NullUUID `json:"repost_of"`
+}
+
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.RepostOf,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.ParentID,
+		&i.DeletedAt,
+		&i.RepostOf,
+		&i.QuoteOf,
+	)
+	return i, err
+}
+
+const deleteChirpById = `-- name: DeleteChirpById :execrows
 DELETE FROM chirps
 WHERE id = $1 and user_id = $2
 `
@@ -60,13 +131,78 @@ type DeleteChirpByIdParams struct {
 	UserID uuid.UUID `json:"user_id"`
 }
 
-func (q *Queries) DeleteChirpById(ctx context.Context, arg DeleteChirpByIdParams) error {
-	_, err := q.db.ExecContext(ctx, deleteChirpById, arg.ID, arg.UserID)
+func (q *Queries) DeleteChirpById(ctx context.Context, arg DeleteChirpByIdParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteChirpById, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRechirp = `-- name: DeleteRechirp :exec
+DELETE FROM chirps
+WHERE user_id = $1 and repost_of = $2
+`
+
+type DeleteRechirpParams struct {
+	UserID   uuid.UUID     `json:"user_id"`
+	RepostOf uuid.NullUUID `json:"repost_of"`
+}
+
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRechirp, arg.UserID, arg.RepostOf)
 	return err
 }
 
+const getChirpAncestors = `-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT c.parent_id, 1 AS depth FROM chirps c
+    WHERE c.id = $1::uuid
+    UNION ALL
+    SELECT c.parent_id, a.depth + 1 FROM chirps c
+    JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT chirps.id, chirps.body, chirps.created_at, chirps.updated_at, chirps.user_id, chirps.body_tsv, chirps.parent_id, chirps.deleted_at, chirps.repost_of, chirps.quote_of FROM chirps
+JOIN ancestors ON chirps.id = ancestors.parent_id
+ORDER BY ancestors.depth DESC
+`
+
+func (q *Queries) GetChirpAncestors(ctx context.Context, chirpID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAncestors, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirpById = `-- name: GetChirpById :one
-SELECT id, body, created_at, updated_at, user_id FROM chirps
+SELECT id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of FROM chirps
 WHERE id = $1
 `
 
@@ -79,17 +215,257 @@ func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
+		&i.BodyTsv,
+		&i.ParentID,
+		&i.DeletedAt,
+		&i.RepostOf,
+		&i.QuoteOf,
 	)
 	return i, err
 }
 
+const getChirpByIdForUpdate = `-- name: GetChirpByIdForUpdate :one
+SELECT id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of FROM chirps
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpByIdForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByIdForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.ParentID,
+		&i.DeletedAt,
+		&i.RepostOf,
+		&i.QuoteOf,
+	)
+	return i, err
+}
+
+const getChirpReplies = `-- name: GetChirpReplies :many
+WITH RECURSIVE replies AS (
+    SELECT c.id FROM chirps c
+    WHERE c.parent_id = $1::uuid
+    UNION ALL
+    SELECT c.id FROM chirps c
+    JOIN replies r ON c.parent_id = r.id
+)
+SELECT chirps.id, chirps.body, chirps.created_at, chirps.updated_at, chirps.user_id, chirps.body_tsv, chirps.parent_id, chirps.deleted_at, chirps.repost_of, chirps.quote_of FROM chirps
+JOIN replies ON chirps.id = replies.id
+ORDER BY chirps.created_at ASC, chirps.id ASC
+`
+
+func (q *Queries) GetChirpReplies(ctx context.Context, chirpID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpReplies, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirps = `-- name: GetChirps :many
-SELECT id, body, created_at, updated_at, user_id FROM chirps
-ORDER BY created_at ASC
+SELECT id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of FROM chirps
+WHERE deleted_at IS NULL
+  AND ($1::uuid IS NULL OR user_id = $1::uuid)
+  AND ($2::timestamp IS NULL OR created_at >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR created_at <= $3::timestamp)
+  AND ($4::timestamp IS NULL
+       OR (created_at, id) > ($4::timestamp, $5::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $6
+`
+
+type GetChirpsParams struct {
+	AuthorID        uuid.NullUUID `json:"author_id"`
+	Since           sql.NullTime  `json:"since"`
+	Until           sql.NullTime  `json:"until"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        uuid.NullUUID `json:"cursor_id"`
+	PageLimit       int32         `json:"page_limit"`
+}
+
+func (q *Queries) GetChirps(ctx context.Context, arg GetChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirps,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIds = `-- name: GetChirpsByIds :many
+SELECT id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of FROM chirps
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetChirpsByIds(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByUser = `-- name: GetChirpsByUser :many
+SELECT id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of FROM chirps
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at, id
+`
+
+func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+SELECT id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of FROM chirps
+WHERE deleted_at IS NULL
+  AND ($1::uuid IS NULL OR user_id = $1::uuid)
+  AND ($2::timestamp IS NULL OR created_at >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR created_at <= $3::timestamp)
+  AND ($4::timestamp IS NULL
+       OR (created_at, id) < ($4::timestamp, $5::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $6
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpsDescParams struct {
+	AuthorID        uuid.NullUUID `json:"author_id"`
+	Since           sql.NullTime  `json:"since"`
+	Until           sql.NullTime  `json:"until"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        uuid.NullUUID `json:"cursor_id"`
+	PageLimit       int32         `json:"page_limit"`
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +479,11 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
 		); err != nil {
 			return nil, err
 		}
@@ -116,3 +497,232 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const getRechirp = `-- name: GetRechirp :one
+SELECT id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of FROM chirps
+WHERE user_id = $1 and repost_of = $2
+`
+
+type GetRechirpParams struct {
+	UserID   uuid.UUID     `json:"user_id"`
+	RepostOf uuid.NullUUID `json:"repost_of"`
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getRechirp, arg.UserID, arg.RepostOf)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.ParentID,
+		&i.DeletedAt,
+		&i.RepostOf,
+		&i.QuoteOf,
+	)
+	return i, err
+}
+
+const getTimeline = `-- name: GetTimeline :many
+SELECT chirps.id, chirps.body, chirps.created_at, chirps.updated_at, chirps.user_id, chirps.body_tsv, chirps.parent_id, chirps.deleted_at, chirps.repost_of, chirps.quote_of FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE follows.follower_id = $1
+  AND chirps.deleted_at IS NULL
+  AND ($2::timestamp IS NULL
+       OR (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $4
+`
+
+type GetTimelineParams struct {
+	FollowerID      uuid.UUID     `json:"follower_id"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        uuid.NullUUID `json:"cursor_id"`
+	PageLimit       int32         `json:"page_limit"`
+}
+
+func (q *Queries) GetTimeline(ctx context.Context, arg GetTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTimeline,
+		arg.FollowerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const handOverRepliedChirps = `-- name: HandOverRepliedChirps :exec
+UPDATE chirps
+SET user_id = $1,
+    body = '',
+    updated_at = $2,
+    deleted_at = $2
+WHERE user_id = $3
+  AND repost_of IS NULL
+  AND EXISTS (
+      SELECT 1 FROM chirps AS replies
+      WHERE replies.parent_id = chirps.id
+  )
+`
+
+type HandOverRepliedChirpsParams struct {
+	NewUserID uuid.UUID `json:"new_user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) HandOverRepliedChirps(ctx context.Context, arg HandOverRepliedChirpsParams) error {
+	_, err := q.db.ExecContext(ctx, handOverRepliedChirps, arg.NewUserID, arg.DeletedAt, arg.UserID)
+	return err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.body, chirps.created_at, chirps.updated_at, chirps.user_id, chirps.body_tsv, chirps.parent_id, chirps.deleted_at, chirps.repost_of, chirps.quote_of, ts_rank(body_tsv, websearch_to_tsquery('english', $1))::real AS rank
+FROM chirps
+WHERE deleted_at IS NULL
+  AND body_tsv @@ websearch_to_tsquery('english', $1)
+ORDER BY rank DESC, created_at DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+type SearchChirpsParams struct {
+	Query      string `json:"query"`
+	PageLimit  int32  `json:"page_limit"`
+	PageOffset int32  `json:"page_offset"`
+}
+
+type SearchChirpsRow struct {
+	ID        uuid.UUID     `json:"id"`
+	Body      string        `json:"body"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	UserID    uuid.UUID     `json:"user_id"`
+	BodyTsv   interface{}   `json:"-"`
+	ParentID  uuid.NullUUID `json:"parent_id"`
+	DeletedAt sql.NullTime  `json:"-"`
+	RepostOf  uuid.NullUUID `json:"repost_of"`
+	QuoteOf   uuid.NullUUID `json:"quote_of"`
+	Rank      float32       `json:"rank"`
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps, arg.Query, arg.PageLimit, arg.PageOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.BodyTsv,
+			&i.ParentID,
+			&i.DeletedAt,
+			&i.RepostOf,
+			&i.QuoteOf,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteChirpById = `-- name: SoftDeleteChirpById :execrows
+UPDATE chirps
+SET body = '',
+    deleted_at = $3,
+    updated_at = $3
+WHERE id = $1 and user_id = $2
+`
+
+type SoftDeleteChirpByIdParams struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) SoftDeleteChirpById(ctx context.Context, arg SoftDeleteChirpByIdParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteChirpById, arg.ID, arg.UserID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2,
+    updated_at = $3
+WHERE id = $1
+  AND deleted_at IS NULL
+RETURNING id, body, created_at, updated_at, user_id, body_tsv, parent_id, deleted_at, repost_of, quote_of
+`
+
+type UpdateChirpBodyParams struct {
+	ID        uuid.UUID `json:"id"`
+	Body      string    `json:"body"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body, arg.UpdatedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.BodyTsv,
+		&i.ParentID,
+		&i.DeletedAt,
+		&i.RepostOf,
+		&i.QuoteOf,
+	)
+	return i, err
+}
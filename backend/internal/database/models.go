@@ -12,27 +12,142 @@ import (
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	Body      string    `json:"body"`
+	ID        uuid.UUID     `json:"id"`
+	Body      string        `json:"body"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	UserID    uuid.UUID     `json:"user_id"`
+	BodyTsv   interface{}   `json:"-"`
+	ParentID  uuid.NullUUID `json:"parent_id"`
+	DeletedAt sql.NullTime  `json:"-"`
+	RepostOf  uuid.NullUUID `json:"repost_of"`
+	QuoteOf   uuid.NullUUID `json:"quote_of"`
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	UserID    uuid.UUID `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ChirpMention struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
 	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID `json:"id"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+type ChirpTag struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Tag       string    `json:"tag"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DeniedAccessToken struct {
+	Jti       string    `json:"jti"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type EmailChange struct {
+	TokenHash string       `json:"token_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	NewEmail  string       `json:"new_email"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type EmailVerification struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Email     string       `json:"email"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type Follow struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ModerationFlag struct {
+	ID         uuid.UUID    `json:"id"`
+	ChirpID    uuid.UUID    `json:"chirp_id"`
+	Matches    []string     `json:"matches"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ResolvedAt sql.NullTime `json:"resolved_at"`
+}
+
+type ModerationWord struct {
+	Word      string    `json:"word"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ModerationWordList struct {
+	ID        bool      `json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PasswordReset struct {
+	TokenHash string       `json:"token_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Name       string       `json:"name"`
+	TokenHash  string       `json:"token_hash"`
+	Scopes     []string     `json:"scopes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
 }
 
 type RefreshToken struct {
-	Token     string       `json:"token"`
+	TokenHash string       `json:"token_hash"`
 	ExpiresAt time.Time    `json:"expires_at"`
 	RevokedAt sql.NullTime `json:"revoked_at"`
 	UserID    uuid.UUID    `json:"user_id"`
 	CreatedAt time.Time    `json:"created_at"`
 	UpdatedAt time.Time    `json:"updated_at"`
+	FamilyID  uuid.UUID    `json:"family_id"`
+}
+
+type Session struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt time.Time    `json:"last_used_at"`
+	UserAgent  string       `json:"user_agent"`
+	IpAddress  string       `json:"ip_address"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
 }
 
 type User struct {
-	ID          uuid.UUID    `json:"id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
-	Email       string       `json:"email"`
-	Password    string       `json:"-"`
-	IsChirpyRed sql.NullBool `json:"is_chirpy_red"`
+	ID              uuid.UUID    `json:"id"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	Email           string       `json:"email"`
+	Password        string       `json:"-"`
+	IsChirpyRed     sql.NullBool `json:"is_chirpy_red"`
+	Handle          string       `json:"handle"`
+	DisplayName     string       `json:"display_name"`
+	Bio             string       `json:"bio"`
+	AvatarUrl       string       `json:"avatar_url"`
+	DeletedAt       sql.NullTime `json:"-"`
+	EmailVerifiedAt sql.NullTime `json:"-"`
 }
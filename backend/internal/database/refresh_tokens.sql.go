@@ -14,80 +14,140 @@ import (
 )
 
 const createRefreshTokenForUser = `-- name: CreateRefreshTokenForUser :one
- INSERT INTO refresh_tokens(token, expires_at, revoked_at, created_at, updated_at, user_id)
+ INSERT INTO refresh_tokens(token_hash, expires_at, revoked_at, created_at, updated_at, user_id, family_id)
  VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
- ) RETURNING token, expires_at, revoked_at, user_id, created_at, updated_at
+    $6,
+    $7
+ ) RETURNING token_hash, expires_at, revoked_at, user_id, created_at, updated_at, family_id
 `
 
 type CreateRefreshTokenForUserParams struct {
-	Token     string       `json:"token"`
+	TokenHash string       `json:"token_hash"`
 	ExpiresAt time.Time    `json:"expires_at"`
 	RevokedAt sql.NullTime `json:"revoked_at"`
 	CreatedAt time.Time    `json:"created_at"`
 	UpdatedAt time.Time    `json:"updated_at"`
 	UserID    uuid.UUID    `json:"user_id"`
+	FamilyID  uuid.UUID    `json:"family_id"`
 }
 
 func (q *Queries) CreateRefreshTokenForUser(ctx context.Context, arg CreateRefreshTokenForUserParams) (RefreshToken, error) {
 	row := q.db.QueryRowContext(ctx, createRefreshTokenForUser,
-		arg.Token,
+		arg.TokenHash,
 		arg.ExpiresAt,
 		arg.RevokedAt,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.UserID,
+		arg.FamilyID,
 	)
 	var i RefreshToken
 	err := row.Scan(
-		&i.Token,
+		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.RevokedAt,
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FamilyID,
 	)
 	return i, err
 }
 
 const getTokenbyToken = `-- name: GetTokenbyToken :one
- SELECT token, expires_at, revoked_at, user_id, created_at, updated_at FROM refresh_tokens
- WHERE token = $1
+ SELECT token_hash, expires_at, revoked_at, user_id, created_at, updated_at, family_id FROM refresh_tokens
+ WHERE token_hash = $1
 `
 
-func (q *Queries) GetTokenbyToken(ctx context.Context, token string) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, getTokenbyToken, token)
+func (q *Queries) GetTokenbyToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getTokenbyToken, tokenHash)
 	var i RefreshToken
 	err := row.Scan(
-		&i.Token,
+		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.RevokedAt,
 		&i.UserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FamilyID,
 	)
 	return i, err
 }
 
-const revokeToken = `-- name: RevokeToken :exec
+const revokeOtherRefreshTokensForUser = `-- name: RevokeOtherRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET updated_at = $3,
+    revoked_at = $3
+WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL
+`
+
+type RevokeOtherRefreshTokensForUserParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	FamilyID  uuid.UUID `json:"family_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) RevokeOtherRefreshTokensForUser(ctx context.Context, arg RevokeOtherRefreshTokensForUserParams) error {
+	_, err := q.db.ExecContext(ctx, revokeOtherRefreshTokensForUser, arg.UserID, arg.FamilyID, arg.UpdatedAt)
+	return err
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET updated_at = $2,
+    revoked_at = $2
+WHERE family_id = $1 AND revoked_at IS NULL
+`
+
+type RevokeRefreshTokenFamilyParams struct {
+	FamilyID  uuid.UUID `json:"family_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, arg RevokeRefreshTokenFamilyParams) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokenFamily, arg.FamilyID, arg.UpdatedAt)
+	return err
+}
+
+const revokeRefreshTokensForUser = `-- name: RevokeRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET updated_at = $2,
+    revoked_at = $2
+WHERE user_id = $1 AND revoked_at IS NULL
+`
+
+type RevokeRefreshTokensForUserParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) RevokeRefreshTokensForUser(ctx context.Context, arg RevokeRefreshTokensForUserParams) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokensForUser, arg.UserID, arg.UpdatedAt)
+	return err
+}
+
+const revokeToken = `-- name: RevokeToken :execrows
 UPDATE refresh_tokens
 SET revoked_at = $2,
     updated_at = $3
-WHERE token = $1
+WHERE token_hash = $1 AND revoked_at IS NULL
 `
 
 type RevokeTokenParams struct {
-	Token     string       `json:"token"`
+	TokenHash string       `json:"token_hash"`
 	RevokedAt sql.NullTime `json:"revoked_at"`
 	UpdatedAt time.Time    `json:"updated_at"`
 }
 
-func (q *Queries) RevokeToken(ctx context.Context, arg RevokeTokenParams) error {
-	_, err := q.db.ExecContext(ctx, revokeToken, arg.Token, arg.RevokedAt, arg.UpdatedAt)
-	return err
+func (q *Queries) RevokeToken(ctx context.Context, arg RevokeTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeToken, arg.TokenHash, arg.RevokedAt, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
@@ -10,18 +10,51 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const anonymizeUser = `-- name: AnonymizeUser :exec
+UPDATE users
+SET email = $2,
+    password = '',
+    handle = $3,
+    display_name = '',
+    bio = '',
+    avatar_url = '',
+    is_chirpy_red = false,
+    updated_at = $4,
+    deleted_at = $4
+WHERE id = $1
+`
+
+type AnonymizeUserParams struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	Handle    string    `json:"handle"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) error {
+	_, err := q.db.ExecContext(ctx, anonymizeUser,
+		arg.ID,
+		arg.Email,
+		arg.Handle,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, password)
+INSERT INTO users (id, created_at, updated_at, email, password, handle)
 VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6
 )
-RETURNING id, created_at, updated_at, email, password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at
 `
 
 type CreateUserParams struct {
@@ -30,6 +63,7 @@ type CreateUserParams struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	Email     string    `json:"email"`
 	Password  string    `json:"-"`
+	Handle    string    `json:"handle"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -39,6 +73,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.UpdatedAt,
 		arg.Email,
 		arg.Password,
+		arg.Handle,
 	)
 	var i User
 	err := row.Scan(
@@ -48,12 +83,58 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.Password,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const ensureDeletedUser = `-- name: EnsureDeletedUser :exec
+INSERT INTO users (id, created_at, updated_at, email, password, handle, deleted_at)
+VALUES (
+    $1,
+    $2,
+    $2,
+    $3,
+    '',
+    $4,
+    $2
+) ON CONFLICT DO NOTHING
+`
+
+type EnsureDeletedUserParams struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Email     string    `json:"email"`
+	Handle    string    `json:"handle"`
+}
+
+func (q *Queries) EnsureDeletedUser(ctx context.Context, arg EnsureDeletedUserParams) error {
+	_, err := q.db.ExecContext(ctx, ensureDeletedUser,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Email,
+		arg.Handle,
+	)
+	return err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at FROM users
 WHERE email = $1
 `
 
@@ -67,10 +148,143 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.Password,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByHandle = `-- name: GetUserByHandle :one
+SELECT id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at FROM users
+WHERE handle = $1
+`
+
+func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.Password,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
+const getUserById = `-- name: GetUserById :one
+SELECT id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.Password,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUsersByHandles = `-- name: GetUsersByHandles :many
+SELECT id, handle FROM users
+WHERE handle = ANY($1::text[])
+`
+
+type GetUsersByHandlesRow struct {
+	ID     uuid.UUID `json:"id"`
+	Handle string    `json:"handle"`
+}
+
+func (q *Queries) GetUsersByHandles(ctx context.Context, handles []string) ([]GetUsersByHandlesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByHandles, pq.Array(handles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUsersByHandlesRow
+	for rows.Next() {
+		var i GetUsersByHandlesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Handle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByIds = `-- name: GetUsersByIds :many
+SELECT id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at FROM users
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetUsersByIds(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.Password,
+			&i.IsChirpyRed,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.DeletedAt,
+			&i.EmailVerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const resetUsers = `-- name: ResetUsers :exec
 DELETE FROM users
 `
@@ -91,22 +305,156 @@ func (q *Queries) SetChirpyRedTrue(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-const updateCredentials = `-- name: UpdateCredentials :one
+const setEmailVerified = `-- name: SetEmailVerified :execrows
+UPDATE users
+SET updated_at = $1,
+    email_verified_at = $1
+WHERE id = $2 AND email = $3
+`
+
+type SetEmailVerifiedParams struct {
+	VerifiedAt time.Time `json:"verified_at"`
+	ID         uuid.UUID `json:"id"`
+	Email      string    `json:"email"`
+}
+
+func (q *Queries) SetEmailVerified(ctx context.Context, arg SetEmailVerifiedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setEmailVerified, arg.VerifiedAt, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
 UPDATE users
 SET email = $2,
-    password = $3
+    updated_at = $3,
+    email_verified_at = $3
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at
 `
 
-type UpdateCredentialsParams struct {
-	ID       uuid.UUID `json:"id"`
-	Email    string    `json:"email"`
-	Password string    `json:"-"`
+type UpdateUserEmailParams struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func (q *Queries) UpdateCredentials(ctx context.Context, arg UpdateCredentialsParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateCredentials, arg.ID, arg.Email, arg.Password)
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.ID, arg.Email, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.Password,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserHandle = `-- name: UpdateUserHandle :one
+UPDATE users
+SET handle = $2,
+    updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at
+`
+
+type UpdateUserHandleParams struct {
+	ID        uuid.UUID `json:"id"`
+	Handle    string    `json:"handle"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) UpdateUserHandle(ctx context.Context, arg UpdateUserHandleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserHandle, arg.ID, arg.Handle, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.Password,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET password = $2,
+    updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at
+`
+
+type UpdateUserPasswordParams struct {
+	ID        uuid.UUID `json:"id"`
+	Password  string    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.Password, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.Password,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET display_name = $2,
+    bio = $3,
+    avatar_url = $4,
+    updated_at = $5
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, password, is_chirpy_red, handle, display_name, bio, avatar_url, deleted_at, email_verified_at
+`
+
+type UpdateUserProfileParams struct {
+	ID          uuid.UUID `json:"id"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarUrl   string    `json:"avatar_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.ID,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.UpdatedAt,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -115,6 +463,12 @@ func (q *Queries) UpdateCredentials(ctx context.Context, arg UpdateCredentialsPa
 		&i.Email,
 		&i.Password,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.DeletedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
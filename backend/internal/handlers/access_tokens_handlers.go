@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+)
+
+var errAccessTokenDenied = errors.New("access token has been revoked")
+
+// validateAccessToken checks the token's signature and claims and rejects
+// tokens that were put on the deny-list.
+func (cfg *ApiConfig) validateAccessToken(tokenString string) (auth.AccessClaims, error) {
+	claims, err := auth.ParseAccessToken(tokenString, cfg.Keys)
+	if err != nil {
+		return auth.AccessClaims{}, err
+	}
+	if cfg.DenyList.IsDenied(claims.TokenID) {
+		return auth.AccessClaims{}, errAccessTokenDenied
+	}
+	return claims, nil
+}
+
+func (cfg *ApiConfig) denyAccessToken(w http.ResponseWriter, req *http.Request, tokenID string, expiresAt time.Time) bool {
+	err := cfg.Queries.DenyAccessToken(req.Context(), database.DenyAccessTokenParams{
+		Jti:       tokenID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke access token",
+			Code:  500,
+		})
+		return false
+	}
+	cfg.DenyList.Deny(tokenID, expiresAt)
+	return true
+}
+
+// RevokeAccessTokenHandler kills the access token used to call it, e.g. on
+// logout, instead of waiting for it to expire.
+func (cfg *ApiConfig) RevokeAccessTokenHandler(w http.ResponseWriter, req *http.Request) {
+	claims := requestClaims(req)
+	if !cfg.denyAccessToken(w, req, claims.TokenID, claims.ExpiresAt) {
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (cfg *ApiConfig) DenyAccessTokenHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Jti       string    `json:"jti"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+	if params.Jti == "" {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("missing jti"),
+			Msg:   "jti is required",
+			Code:  400,
+		})
+		return
+	}
+	// Access tokens never outlive the configured TTL, so that is a safe
+	// upper bound when the caller doesn't know the exact expiry.
+	if params.ExpiresAt.IsZero() {
+		params.ExpiresAt = time.Now().Add(cfg.AccessTokenTTL)
+	}
+
+	if !cfg.denyAccessToken(w, req, params.Jti, params.ExpiresAt) {
+		return
+	}
+	w.WriteHeader(204)
+}
@@ -0,0 +1,551 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/ShkolZ/chirpy/backend/internal/mailer"
+	"github.com/google/uuid"
+)
+
+const emailChangeTTL = 24 * time.Hour
+
+var errInvalidEmail = errors.New("email is not a valid address")
+
+// parseEmail accepts a bare address like "jo@example.com". Display names such
+// as "Jo <jo@example.com>" are rejected.
+func parseEmail(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	addr, err := mail.ParseAddress(raw)
+	if err != nil || addr.Address != raw {
+		return "", errInvalidEmail
+	}
+	return addr.Address, nil
+}
+
+func respondWithUserUpdateError(w http.ResponseWriter, req *http.Request, err error) {
+	code := 500
+	msg := "Couldn't update user"
+	switch {
+	case helpers.IsUniqueViolationOn(err, "users_email_key"):
+		code = 409
+		msg = "Email is already taken"
+	case errors.Is(err, sql.ErrNoRows):
+		code = 404
+		msg = "User not found"
+	}
+	helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+		Error: err,
+		Msg:   msg,
+		Code:  code,
+	})
+}
+
+// checkPassword loads the user and compares password against their hash.
+// The returned code is 0 when the password matches.
+func (cfg *ApiConfig) checkPassword(req *http.Request, userID uuid.UUID, password string) (database.User, int, error) {
+	user, err := cfg.Queries.GetUserById(req.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, 404, err
+	}
+	if err != nil {
+		return database.User{}, 500, err
+	}
+	if user.DeletedAt.Valid {
+		return database.User{}, 404, fmt.Errorf("user %v is deleted", userID)
+	}
+	ok, err := auth.CheckPasswordHash(password, user.Password)
+	if err != nil {
+		return database.User{}, 500, err
+	}
+	if !ok {
+		return database.User{}, 401, errors.New("wrong password")
+	}
+	return user, 0, nil
+}
+
+func (cfg *ApiConfig) ChangePasswordHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+
+	userID := requestUserID(req)
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+	if params.NewPassword == "" {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("empty password"),
+			Msg:   "New password must not be empty",
+			Code:  400,
+		})
+		return
+	}
+
+	if _, code, err := cfg.checkPassword(req, userID, params.CurrentPassword); err != nil {
+		msg := "Couldn't check password"
+		if code == 401 {
+			msg = "Current password is wrong"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	if _, ok := cfg.changePassword(w, req, userID, params.NewPassword); !ok {
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// changePassword sets a new password and signs the user out everywhere but
+// the session the request was made from, so a leaked password stops working
+// on other devices. It writes the error response itself and reports whether
+// the password was changed.
+func (cfg *ApiConfig) changePassword(w http.ResponseWriter, req *http.Request, userID uuid.UUID, password string) (database.User, bool) {
+	passHash, err := auth.HashPassword(password)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't hash the password",
+			Code:  500,
+		})
+		return database.User{}, false
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't change password",
+			Code:  500,
+		})
+		return database.User{}, false
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	user, err := qtx.UpdateUserPassword(req.Context(), database.UpdateUserPasswordParams{
+		ID:        userID,
+		Password:  passHash,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		respondWithUserUpdateError(w, req, err)
+		return database.User{}, false
+	}
+	if err := revokeOtherSessions(req.Context(), qtx, userID, requestClaims(req).SessionID); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke sessions",
+			Code:  500,
+		})
+		return database.User{}, false
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't change password",
+			Code:  500,
+		})
+		return database.User{}, false
+	}
+	return user, true
+}
+
+// startEmailChange stores a pending change to newEmail and mails the
+// confirmation token there. It writes the error response itself and
+// reports whether the change was started.
+func (cfg *ApiConfig) startEmailChange(w http.ResponseWriter, req *http.Request, userID uuid.UUID, newEmail string) bool {
+	if _, err := cfg.Queries.GetUserByEmail(req.Context(), newEmail); err == nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("email %v is taken", newEmail),
+			Msg:   "Email is already taken",
+			Code:  409,
+		})
+		return false
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't check email",
+			Code:  500,
+		})
+		return false
+	}
+
+	token, err := auth.MakeSecretToken()
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't make token",
+			Code:  500,
+		})
+		return false
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't start email change",
+			Code:  500,
+		})
+		return false
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	if err := qtx.DeleteEmailChangesForUser(req.Context(), userID); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't start email change",
+			Code:  500,
+		})
+		return false
+	}
+	err = qtx.CreateEmailChange(req.Context(), database.CreateEmailChangeParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    userID,
+		NewEmail:  newEmail,
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't start email change",
+			Code:  500,
+		})
+		return false
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't start email change",
+			Code:  500,
+		})
+		return false
+	}
+	err = cfg.Mailer.Send(req.Context(), mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new Chirpy email",
+		Body:    fmt.Sprintf("Use this token to confirm your new email address: %s\nIt expires in %v.", token, emailChangeTTL),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't send confirmation email",
+			Code:  500,
+		})
+		return false
+	}
+	return true
+}
+
+// RequestEmailChangeHandler mails a confirmation token to the new address.
+// The email on the account only changes once the token comes back.
+func (cfg *ApiConfig) RequestEmailChangeHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		NewEmail string `json:"new_email"`
+		Password string `json:"password"`
+	}
+
+	userID := requestUserID(req)
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+	newEmail, err := parseEmail(params.NewEmail)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	user, code, err := cfg.checkPassword(req, userID, params.Password)
+	if err != nil {
+		msg := "Couldn't check password"
+		if code == 401 {
+			msg = "Password is wrong"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("same email"),
+			Msg:   "New email is the same as the current one",
+			Code:  400,
+		})
+		return
+	}
+	if !cfg.startEmailChange(w, req, userID, newEmail) {
+		return
+	}
+
+	w.WriteHeader(202)
+}
+
+func (cfg *ApiConfig) ConfirmEmailChangeHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Token string `json:"token"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't confirm email change",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	tokenHash := auth.HashToken(params.Token)
+	change, err := qtx.GetEmailChange(req.Context(), tokenHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't confirm email change",
+			Code:  500,
+		})
+		return
+	}
+	if err != nil || change.UsedAt.Valid || time.Now().After(change.ExpiresAt) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("unknown, used or expired email change token"),
+			Msg:   "Token is invalid or expired",
+			Code:  400,
+		})
+		return
+	}
+
+	used, err := qtx.UseEmailChange(req.Context(), database.UseEmailChangeParams{
+		TokenHash: tokenHash,
+		UsedAt:    sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't confirm email change",
+			Code:  500,
+		})
+		return
+	}
+	if used == 0 {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("email change token was already used"),
+			Msg:   "Token is invalid or expired",
+			Code:  400,
+		})
+		return
+	}
+
+	user, err := qtx.UpdateUserEmail(req.Context(), database.UpdateUserEmailParams{
+		ID:        change.UserID,
+		Email:     change.NewEmail,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		respondWithUserUpdateError(w, req, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't confirm email change",
+			Code:  500,
+		})
+		return
+	}
+
+	data, _ := json.Marshal(user)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+// deletedUserID owns the placeholders of chirps whose author hard-deleted
+// their account.
+var deletedUserID = uuid.Nil
+
+// tombstone returns the email and handle a deleted account is left with.
+// Neither can be registered by a real user.
+func tombstone(userID uuid.UUID) (email, handle string) {
+	hex := strings.ReplaceAll(userID.String(), "-", "")
+	return "deleted+" + hex + "@invalid", "deleted_" + hex
+}
+
+// DeleteAccountHandler removes the caller's account. By default everything
+// cascades except blanked placeholders for chirps with replies; with
+// "anonymize" the chirps stay up under a scrubbed tombstone user and
+// everything else tied to the account is removed.
+func (cfg *ApiConfig) DeleteAccountHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Password  string `json:"password"`
+		Anonymize bool   `json:"anonymize"`
+	}
+
+	userID := requestUserID(req)
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+
+	if _, code, err := cfg.checkPassword(req, userID, params.Password); err != nil {
+		msg := "Couldn't check password"
+		if code == 401 {
+			msg = "Password is wrong"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't delete account",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	var steps []func() error
+	if params.Anonymize {
+		steps = []func() error{
+			func() error { return qtx.DeleteSessionsForUser(req.Context(), userID) },
+			func() error {
+				return qtx.RevokePersonalAccessTokensForUser(req.Context(), database.RevokePersonalAccessTokensForUserParams{
+					RevokedAt: sql.NullTime{Time: time.Now(), Valid: true},
+					UserID:    userID,
+				})
+			},
+			func() error { return qtx.DeleteEmailChangesForUser(req.Context(), userID) },
+			func() error { return qtx.DeleteFollowsForUser(req.Context(), userID) },
+			func() error { return qtx.DeleteLikesByUser(req.Context(), userID) },
+			func() error {
+				email, handle := tombstone(userID)
+				return qtx.AnonymizeUser(req.Context(), database.AnonymizeUserParams{
+					ID:        userID,
+					Email:     email,
+					Handle:    handle,
+					UpdatedAt: time.Now(),
+				})
+			},
+		}
+	} else {
+		// Chirps other people replied to are handed to the shared deleted
+		// user as placeholders, so the cascade below doesn't cut those
+		// replies loose from their thread.
+		steps = []func() error{
+			func() error {
+				email, handle := tombstone(deletedUserID)
+				return qtx.EnsureDeletedUser(req.Context(), database.EnsureDeletedUserParams{
+					ID:        deletedUserID,
+					CreatedAt: time.Now(),
+					Email:     email,
+					Handle:    handle,
+				})
+			},
+			func() error { return qtx.DeleteChirpRevisionsForUser(req.Context(), userID) },
+			func() error {
+				return qtx.HandOverRepliedChirps(req.Context(), database.HandOverRepliedChirpsParams{
+					NewUserID: deletedUserID,
+					DeletedAt: time.Now(),
+					UserID:    userID,
+				})
+			},
+			func() error { return qtx.DeleteUser(req.Context(), userID) },
+		}
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "Couldn't delete account",
+				Code:  500,
+			})
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't delete account",
+			Code:  500,
+		})
+		return
+	}
+
+	// The access token used here would otherwise keep working until it
+	// expires.
+	claims := requestClaims(req)
+	if !cfg.denyAccessToken(w, req, claims.TokenID, claims.ExpiresAt) {
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestChangePasswordSignsOutOtherSessions(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   func(current, next string) map[string]string
+		want   int
+	}{
+		{
+			name:   "change password",
+			target: "/api/users/me/password",
+			body: func(current, next string) map[string]string {
+				return map[string]string{"current_password": current, "new_password": next}
+			},
+			want: 204,
+		},
+		{
+			name:   "update credentials",
+			target: "/api/users",
+			body: func(current, next string) map[string]string {
+				return map[string]string{"current_password": current, "password": next}
+			},
+			want: 200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, h := newTestServer(t)
+			user := signUp(t, cfg, h, "alice")
+			_, otherRefreshToken := logIn(t, h, user.Email, user.Password)
+
+			newPassword := "a much longer and newer password"
+			rec := doRequest(t, h, "PUT", tt.target, user.Token, tt.body(user.Password, newPassword))
+			if rec.Code != tt.want {
+				t.Fatalf("changing password: %d %s", rec.Code, rec.Body)
+			}
+
+			rec = doRequest(t, h, "POST", "/api/refresh", otherRefreshToken, nil)
+			if rec.Code != 401 {
+				t.Errorf("refreshing another session: got %d, want 401", rec.Code)
+			}
+			rec = doRequest(t, h, "POST", "/api/refresh", user.RefreshToken, nil)
+			if rec.Code != 200 {
+				t.Errorf("refreshing the caller's session: got %d %s, want 200", rec.Code, rec.Body)
+			}
+			logIn(t, h, user.Email, newPassword)
+		})
+	}
+}
@@ -1,16 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ShkolZ/chirpy/backend/internal/auth"
 	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/handles"
 	"github.com/ShkolZ/chirpy/backend/internal/helpers"
 	"github.com/google/uuid"
 )
@@ -19,6 +22,7 @@ func (cfg *ApiConfig) CreateUserHandler(w http.ResponseWriter, req *http.Request
 	type reqParams struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Handle   string `json:"handle"`
 	}
 
 	params := reqParams{}
@@ -33,6 +37,30 @@ func (cfg *ApiConfig) CreateUserHandler(w http.ResponseWriter, req *http.Request
 		return
 	}
 
+	email, err := parseEmail(params.Email)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	userID := uuid.New()
+	handle := handles.ForUser(userID)
+	if params.Handle != "" {
+		handle, err = handles.Parse(params.Handle)
+		if err != nil {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   err.Error(),
+				Code:  400,
+			})
+			return
+		}
+	}
+
 	hashedPass, err := auth.HashPassword(params.Password)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
@@ -44,12 +72,29 @@ func (cfg *ApiConfig) CreateUserHandler(w http.ResponseWriter, req *http.Request
 	}
 
 	user, err := cfg.Queries.CreateUser(req.Context(), database.CreateUserParams{
-		ID:        uuid.New(),
+		ID:        userID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-		Email:     params.Email,
+		Email:     email,
 		Password:  hashedPass,
+		Handle:    handle,
 	})
+	if helpers.IsUniqueViolationOn(err, "users_handle_idx") {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Handle is already taken",
+			Code:  409,
+		})
+		return
+	}
+	if helpers.IsUniqueViolationOn(err, "users_email_key") {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Email is already taken",
+			Code:  409,
+		})
+		return
+	}
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
@@ -59,6 +104,9 @@ func (cfg *ApiConfig) CreateUserHandler(w http.ResponseWriter, req *http.Request
 		return
 	}
 	log.Println("User was Created")
+	if err := cfg.sendVerificationEmail(req.Context(), user); err != nil {
+		log.Printf("Couldn't send verification email to user %v: %v", user.ID, err)
+	}
 
 	user.Password = params.Password
 	data, _ := json.Marshal(user)
@@ -67,80 +115,158 @@ func (cfg *ApiConfig) CreateUserHandler(w http.ResponseWriter, req *http.Request
 	w.Write(data)
 }
 
+// UpdateCredentialsHandler changes the password and/or email in one call.
+// It needs the current password, and a new email only takes effect once it
+// is confirmed through the same flow as RequestEmailChangeHandler, in which
+// case the response is 202.
 func (cfg *ApiConfig) UpdateCredentialsHandler(w http.ResponseWriter, req *http.Request) {
 	type reqParams struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email           string `json:"email"`
+		Password        string `json:"password"`
+		CurrentPassword string `json:"current_password"`
 	}
 
-	token, err := auth.GetBearerToken(req.Header)
+	userID := requestUserID(req)
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(&params)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "No token in the header",
-			Code:  401,
+			Msg:   "Couldnt decode",
+			Code:  400,
+		})
+		return
+	}
+	if params.Email == "" && params.Password == "" {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("nothing to update"),
+			Msg:   "Provide a new email or password",
+			Code:  400,
 		})
 		return
 	}
+	email := ""
+	if params.Email != "" {
+		email, err = parseEmail(params.Email)
+		if err != nil {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   err.Error(),
+				Code:  400,
+			})
+			return
+		}
+	}
 
-	params := reqParams{}
-	decoder := json.NewDecoder(req.Body)
-	err = decoder.Decode(&params)
+	user, code, err := cfg.checkPassword(req, userID, params.CurrentPassword)
 	if err != nil {
+		msg := "Couldn't check password"
+		if code == 401 {
+			msg = "Current password is wrong"
+		}
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Couldnt decode",
-			Code:  401,
+			Msg:   msg,
+			Code:  code,
 		})
 		return
 	}
 
-	userID, err := auth.ValidateJWT(token, cfg.SecretKey)
+	// The email change goes first: it can still fail with a 409, and the
+	// password should not change on a request that is rejected.
+	status := 200
+	if email != "" && !strings.EqualFold(email, user.Email) {
+		if !cfg.startEmailChange(w, req, userID, email) {
+			return
+		}
+		status = 202
+	}
+
+	if params.Password != "" {
+		var ok bool
+		user, ok = cfg.changePassword(w, req, userID, params.Password)
+		if !ok {
+			return
+		}
+	}
+
+	data, err := json.Marshal(user)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Couldn't validate JWT",
-			Code:  401,
+			Msg:   "Could marshal json",
+			Code:  500,
 		})
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) UpdateHandleHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Handle string `json:"handle"`
+	}
 
-	passHash, err := auth.HashPassword(params.Password)
+	userID := requestUserID(req)
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+	handle, err := handles.Parse(params.Handle)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Couldn't hash the password",
-			Code:  401,
+			Msg:   err.Error(),
+			Code:  400,
 		})
 		return
 	}
 
-	user, err := cfg.Queries.UpdateCredentials(req.Context(), database.UpdateCredentialsParams{
-		ID:       userID,
-		Email:    params.Email,
-		Password: passHash,
+	user, err := cfg.Queries.UpdateUserHandle(req.Context(), database.UpdateUserHandleParams{
+		ID:        userID,
+		Handle:    handle,
+		UpdatedAt: time.Now(),
 	})
-	if err != nil {
+	if helpers.IsUniqueViolationOn(err, "users_handle_idx") {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Couldn't update user credentials",
-			Code:  401,
+			Msg:   "Handle is already taken",
+			Code:  409,
+		})
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "User not found",
+			Code:  404,
 		})
 		return
 	}
-	data, err := json.Marshal(user)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Could marshal json",
-			Code:  401,
+			Msg:   "Couldn't update handle",
+			Code:  500,
 		})
 		return
 	}
+
+	data, _ := json.Marshal(user)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 	w.Write(data)
-
 }
 
 func (cfg *ApiConfig) LoginHandler(w http.ResponseWriter, req *http.Request) {
@@ -184,41 +310,30 @@ func (cfg *ApiConfig) LoginHandler(w http.ResponseWriter, req *http.Request) {
 			Msg:   "Problem with comparing hashes",
 			Code:  500,
 		})
+		return
 	}
 
 	if isPassword {
-		var tokenString string
-		tokenString, err = auth.MakeJWT(user.ID, cfg.SecretKey)
+		refToken, sessionID, err := startSession(req.Context(), cfg.Queries, req, user.ID, cfg.RefreshTokenTTL)
 		if err != nil {
 			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 				Error: err,
-				Msg:   "Some problem with making JWT",
+				Msg:   "Couldn make refresh token",
 				Code:  500,
 			})
+			return
 		}
 
-		refToken, err := auth.MakeRefreshToken()
+		tokenString, err := auth.MakeJWT(user.ID, sessionID, cfg.Keys, cfg.AccessTokenTTL)
 		if err != nil {
 			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 				Error: err,
-				Msg:   "Couldn make refresh token",
+				Msg:   "Some problem with making JWT",
 				Code:  500,
 			})
 			return
 		}
 
-		cfg.Queries.CreateRefreshTokenForUser(req.Context(), database.CreateRefreshTokenForUserParams{
-			Token:     refToken,
-			ExpiresAt: time.Now().Add(60 * 24 * time.Hour),
-			RevokedAt: sql.NullTime{
-				Time:  time.Time{},
-				Valid: false,
-			},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			UserID:    user.ID,
-		})
-
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(200)
 		data, _ := json.Marshal(loginResponse{
@@ -236,9 +351,37 @@ func (cfg *ApiConfig) LoginHandler(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// createRefreshToken stores a new refresh token in familyID, the session it
+// belongs to. Every rotation adds to the same family.
+func createRefreshToken(ctx context.Context, q *database.Queries, userID, familyID uuid.UUID, ttl time.Duration) (string, error) {
+	refToken, err := auth.MakeRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = q.CreateRefreshTokenForUser(ctx, database.CreateRefreshTokenForUserParams{
+		TokenHash: auth.HashToken(refToken),
+		ExpiresAt: time.Now().Add(ttl),
+		RevokedAt: sql.NullTime{
+			Time:  time.Time{},
+			Valid: false,
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    userID,
+		FamilyID:  familyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return refToken, nil
+}
+
+// RefreshTokenHandler rotates the refresh token on every use. A revoked token
+// coming back means it was copied, so its whole family is revoked.
 func (cfg *ApiConfig) RefreshTokenHandler(w http.ResponseWriter, req *http.Request) {
 	type response struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
 	}
 	refToken, err := auth.GetBearerToken(req.Header)
 	if err != nil {
@@ -250,7 +393,7 @@ func (cfg *ApiConfig) RefreshTokenHandler(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	dbToken, err := cfg.Queries.GetTokenbyToken(req.Context(), refToken)
+	dbToken, err := cfg.Queries.GetTokenbyToken(req.Context(), auth.HashToken(refToken))
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
@@ -260,46 +403,151 @@ func (cfg *ApiConfig) RefreshTokenHandler(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	if dbToken.RevokedAt.Valid == true || time.Now().After(dbToken.ExpiresAt) {
+	if dbToken.RevokedAt.Valid {
+		cfg.revokeTokenFamily(w, req, dbToken)
+		return
+	}
+	if time.Now().After(dbToken.ExpiresAt) {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
-			Error: err,
+			Error: errors.New("refresh token expired"),
 			Msg:   "Non Valid refresh token",
 			Code:  401,
 		})
 		return
 	}
-	token, err := auth.MakeJWT(dbToken.UserID, cfg.SecretKey)
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't rotate refresh token",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	revoked, err := qtx.RevokeToken(req.Context(), database.RevokeTokenParams{
+		TokenHash: auth.HashToken(refToken),
+		RevokedAt: sql.NullTime{
+			Time:  time.Now(),
+			Valid: true,
+		},
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't rotate refresh token",
+			Code:  500,
+		})
+		return
+	}
+	if revoked == 0 {
+		// Another request rotated this token first.
+		tx.Rollback()
+		cfg.revokeTokenFamily(w, req, dbToken)
+		return
+	}
+	newRefToken, err := createRefreshToken(req.Context(), qtx, dbToken.UserID, dbToken.FamilyID, cfg.RefreshTokenTTL)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't rotate refresh token",
+			Code:  500,
+		})
+		return
+	}
+	err = qtx.TouchSession(req.Context(), database.TouchSessionParams{
+		ID:         dbToken.FamilyID,
+		LastUsedAt: time.Now(),
+		UserAgent:  req.UserAgent(),
+		IpAddress:  clientIP(req),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't rotate refresh token",
+			Code:  500,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't rotate refresh token",
+			Code:  500,
+		})
+		return
+	}
+
+	token, err := auth.MakeJWT(dbToken.UserID, dbToken.FamilyID, cfg.Keys, cfg.AccessTokenTTL)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
 			Msg:   "Couldnt make jwt",
 			Code:  500,
 		})
+		return
 	}
 	data, _ := json.Marshal(response{
-		Token: token,
+		Token:        token,
+		RefreshToken: newRefToken,
 	})
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 	w.Write(data)
 }
 
-func (cfg *ApiConfig) RevokeRefreshTokenHandler(w http.ResponseWriter, req *http.Request) {
-	refToken, _ := auth.GetBearerToken(req.Header)
-	err := cfg.Queries.RevokeToken(req.Context(), database.RevokeTokenParams{
-		Token: refToken,
-		RevokedAt: sql.NullTime{
-			Time:  time.Now(),
-			Valid: true,
-		},
-		UpdatedAt: time.Now(),
+func (cfg *ApiConfig) revokeTokenFamily(w http.ResponseWriter, req *http.Request, dbToken database.RefreshToken) {
+	_, err := revokeSession(req.Context(), cfg.Queries, dbToken.FamilyID, dbToken.UserID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke refresh tokens",
+			Code:  500,
+		})
+		return
+	}
+	helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+		Error: fmt.Errorf("revoked refresh token reused, family %v revoked", dbToken.FamilyID),
+		Msg:   "Non Valid refresh token",
+		Code:  401,
 	})
+}
+
+// RevokeRefreshTokenHandler logs out the session the presented refresh token
+// belongs to.
+func (cfg *ApiConfig) RevokeRefreshTokenHandler(w http.ResponseWriter, req *http.Request) {
+	refToken, err := auth.GetBearerToken(req.Header)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Could revoke the token",
+			Msg:   "Couldn't get Bearer token",
 			Code:  400,
 		})
+		return
+	}
+	dbToken, err := cfg.Queries.GetTokenbyToken(req.Context(), auth.HashToken(refToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Token doesnt exist in db",
+			Code:  401,
+		})
+		return
+	}
+	if err == nil {
+		_, err = revokeSession(req.Context(), cfg.Queries, dbToken.FamilyID, dbToken.UserID)
+	}
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Could revoke the token",
+			Code:  500,
+		})
+		return
 	}
 	w.WriteHeader(204)
 	w.Write([]byte("OK"))
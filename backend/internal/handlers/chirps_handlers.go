@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
-	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/chirps"
 	"github.com/ShkolZ/chirpy/backend/internal/database"
 	"github.com/ShkolZ/chirpy/backend/internal/helpers"
 	"github.com/google/uuid"
@@ -32,35 +37,43 @@ func (cfg *ApiConfig) ValidateChirpHandler(w http.ResponseWriter, req *http.Requ
 		})
 		return
 	}
-	count := 0
-	stringToCheck := params.Body
-	for range stringToCheck {
-		count++
+	validated, err := chirps.Validate(params.Body, cfg.Moderation)
+	if err != nil {
+		respondWithValidationError(w, req, err)
+		return
 	}
-	if count >= 0 && count <= 140 {
-		log.Printf("Chirp is valid\n")
+	log.Printf("Chirp is valid\n")
 
-		cleanString := helpers.CleanInput(stringToCheck)
-		data, _ := json.Marshal(validResponse{CleanBody: cleanString})
+	data, _ := json.Marshal(validResponse{CleanBody: validated.Body})
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(200)
-		w.Write(data)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
 
-	} else {
+func respondWithValidationError(w http.ResponseWriter, req *http.Request, err error) {
+	var validationErrs chirps.ValidationErrors
+	if !errors.As(err, &validationErrs) {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
-			Error: errors.New("Chirp is too long"),
-			Msg:   "Chirp is too long",
-			Code:  400,
+			Error: err,
+			Msg:   "Couldn't validate chirp",
+			Code:  500,
 		})
 		return
 	}
-
+	helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+		Error:   err,
+		Msg:     validationErrs[0].Message,
+		Code:    400,
+		Details: validationErrs,
+	})
 }
 
 func (cfg *ApiConfig) CreateChirpHandler(w http.ResponseWriter, req *http.Request) {
 	type reqParams struct {
-		Body string `json:"body"`
+		Body      string     `json:"body"`
+		InReplyTo *uuid.UUID `json:"in_reply_to"`
+		QuoteOf   *uuid.UUID `json:"quote_of"`
 	}
 	params := reqParams{}
 	decoder := json.NewDecoder(req.Body)
@@ -72,31 +85,79 @@ func (cfg *ApiConfig) CreateChirpHandler(w http.ResponseWriter, req *http.Reques
 		})
 		return
 	}
-	tokenString, err := auth.GetBearerToken(req.Header)
+	userID := requestUserID(req)
+	if cfg.respondIfUnverified(w, req, userID) {
+		return
+	}
+
+	validated, err := chirps.Validate(params.Body, cfg.Moderation)
 	if err != nil {
-		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
-			Error: err,
-			Msg:   "Some problem with getting token",
-			Code:  401,
-		})
+		respondWithValidationError(w, req, err)
 		return
 	}
-	userID, err := auth.ValidateJWT(tokenString, cfg.SecretKey)
+
+	parentID := uuid.NullUUID{}
+	if params.InReplyTo != nil {
+		parent, err := cfg.Queries.GetChirpById(req.Context(), *params.InReplyTo)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "Some error getting chirp by id",
+				Code:  500,
+			})
+			return
+		}
+		if err != nil || parent.DeletedAt.Valid {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: fmt.Errorf("parent chirp %v not found", *params.InReplyTo),
+				Msg:   "Chirp you are replying to doesn't exist",
+				Code:  404,
+			})
+			return
+		}
+		parentID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+	}
+
+	quoteOf := uuid.NullUUID{}
+	if params.QuoteOf != nil {
+		quoted, err := cfg.shareableChirp(req.Context(), *params.QuoteOf)
+		if err != nil {
+			code := 500
+			msg := "Some error getting chirp by id"
+			if errors.Is(err, errChirpNotFound) {
+				code = 404
+				msg = "Chirp you are quoting doesn't exist"
+			}
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   msg,
+				Code:  code,
+			})
+			return
+		}
+		quoteOf = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Not valid jwt",
-			Code:  401,
+			Msg:   "Some error creating chirp",
+			Code:  500,
 		})
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
 
-	chirp, err := cfg.Queries.CreateChirp(req.Context(), database.CreateChirpParams{
+	chirp, err := qtx.CreateChirp(req.Context(), database.CreateChirpParams{
 		ID:        uuid.New(),
-		Body:      params.Body,
+		Body:      validated.Body,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		UserID:    userID,
+		ParentID:  parentID,
+		QuoteOf:   quoteOf,
 	})
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
@@ -106,8 +167,43 @@ func (cfg *ApiConfig) CreateChirpHandler(w http.ResponseWriter, req *http.Reques
 		})
 		return
 	}
+	if err := setChirpTags(req.Context(), qtx, chirp, validated.Tags); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't save chirp tags",
+			Code:  500,
+		})
+		return
+	}
+	if err := setChirpMentions(req.Context(), qtx, chirp, validated.Mentions); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't save chirp mentions",
+			Code:  500,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error creating chirp",
+			Code:  500,
+		})
+		return
+	}
+	cfg.flagChirp(req.Context(), chirp.ID, validated.Flags)
 
-	data, _ := json.Marshal(chirp)
+	res, err := cfg.chirpResponse(req.Context(), chirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirp",
+			Code:  500,
+		})
+		return
+	}
+
+	data, _ := json.Marshal(res)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(201)
@@ -115,9 +211,56 @@ func (cfg *ApiConfig) CreateChirpHandler(w http.ResponseWriter, req *http.Reques
 
 }
 
+type keysetCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID `json:"id"`
+}
+
+type chirpsPage struct {
+	Chirps     []chirpResponse `json:"chirps"`
+	NextCursor *string         `json:"next_cursor"`
+}
+
 func (cfg *ApiConfig) GetChirpsHandler(w http.ResponseWriter, req *http.Request) {
+	viewerID := optionalRequestUserID(req)
+
+	query := req.URL.Query()
+
+	limit, err := helpers.ParsePageLimit(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
 
-	chirps, err := cfg.Queries.GetChirps(req.Context())
+	params, err := parseChirpsFilter(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+	params.PageLimit = limit + 1
+
+	var chirps []database.Chirp
+	switch query.Get("sort") {
+	case "", "asc":
+		chirps, err = cfg.Queries.GetChirps(req.Context(), params)
+	case "desc":
+		chirps, err = cfg.Queries.GetChirpsDesc(req.Context(), database.GetChirpsDescParams(params))
+	default:
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("unknown sort %q", query.Get("sort")),
+			Msg:   "sort must be either asc or desc",
+			Code:  400,
+		})
+		return
+	}
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
@@ -127,7 +270,263 @@ func (cfg *ApiConfig) GetChirpsHandler(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	data, err := json.Marshal(chirps)
+	page, err := cfg.newChirpsPage(req.Context(), chirps, limit, viewerID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshaling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func parseChirpsFilter(query url.Values) (database.GetChirpsParams, error) {
+	params := database.GetChirpsParams{}
+
+	if rawAuthor := query.Get("author_id"); rawAuthor != "" {
+		authorID, err := uuid.Parse(rawAuthor)
+		if err != nil {
+			return params, errors.New("author_id must be a valid UUID")
+		}
+		params.AuthorID = uuid.NullUUID{UUID: authorID, Valid: true}
+	}
+
+	if rawSince := query.Get("since"); rawSince != "" {
+		since, err := time.Parse(time.RFC3339, rawSince)
+		if err != nil {
+			return params, errors.New("since must be an RFC 3339 timestamp, e.g. 2006-01-02T15:04:05Z")
+		}
+		params.Since = sql.NullTime{Time: since.Local(), Valid: true}
+	}
+
+	if rawUntil := query.Get("until"); rawUntil != "" {
+		until, err := time.Parse(time.RFC3339, rawUntil)
+		if err != nil {
+			return params, errors.New("until must be an RFC 3339 timestamp, e.g. 2006-01-02T15:04:05Z")
+		}
+		params.Until = sql.NullTime{Time: until.Local(), Valid: true}
+	}
+
+	if params.Since.Valid && params.Until.Valid && params.Until.Time.Before(params.Since.Time) {
+		return params, errors.New("until must not be before since")
+	}
+
+	cursorCreatedAt, cursorID, err := parseKeysetCursor(query)
+	if err != nil {
+		return params, err
+	}
+	params.CursorCreatedAt = cursorCreatedAt
+	params.CursorID = cursorID
+
+	return params, nil
+}
+
+func parseKeysetCursor(query url.Values) (sql.NullTime, uuid.NullUUID, error) {
+	rawCursor := query.Get("cursor")
+	if rawCursor == "" {
+		return sql.NullTime{}, uuid.NullUUID{}, nil
+	}
+	cursor := keysetCursor{}
+	if err := helpers.DecodeCursor(rawCursor, &cursor); err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, err
+	}
+	return sql.NullTime{Time: cursor.CreatedAt, Valid: true}, uuid.NullUUID{UUID: cursor.ID, Valid: true}, nil
+}
+
+// newChirpsPage expects chirps to be fetched with limit+1 rows, the extra row
+// only tells us whether there is a next page.
+func (cfg *ApiConfig) newChirpsPage(ctx context.Context, chirps []database.Chirp, limit int32, viewerID uuid.NullUUID) (chirpsPage, error) {
+	page := chirpsPage{}
+	if len(chirps) > int(limit) {
+		chirps = chirps[:limit]
+		last := chirps[len(chirps)-1]
+		next, err := helpers.EncodeCursor(keysetCursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+		if err != nil {
+			return chirpsPage{}, err
+		}
+		page.NextCursor = &next
+	}
+	responses, err := cfg.chirpResponses(ctx, chirps, viewerID)
+	if err != nil {
+		return chirpsPage{}, err
+	}
+	page.Chirps = responses
+	return page, nil
+}
+
+func (cfg *ApiConfig) GetTimelineHandler(w http.ResponseWriter, req *http.Request) {
+	userID := requestUserID(req)
+
+	query := req.URL.Query()
+	limit, err := helpers.ParsePageLimit(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+	cursorCreatedAt, cursorID, err := parseKeysetCursor(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	chirps, err := cfg.Queries.GetTimeline(req.Context(), database.GetTimelineParams{
+		FollowerID:      userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get timeline",
+			Code:  500,
+		})
+		return
+	}
+
+	page, err := cfg.newChirpsPage(req.Context(), chirps, limit, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshaling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+type searchCursor struct {
+	Offset int32 `json:"offset"`
+}
+
+func (cfg *ApiConfig) SearchChirpsHandler(w http.ResponseWriter, req *http.Request) {
+	viewerID := optionalRequestUserID(req)
+
+	query := req.URL.Query()
+
+	searchQuery := strings.TrimSpace(query.Get("q"))
+	if searchQuery == "" {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("empty search query"),
+			Msg:   "q must not be empty",
+			Code:  400,
+		})
+		return
+	}
+
+	limit, err := helpers.ParsePageLimit(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	cursor := searchCursor{}
+	if rawCursor := query.Get("cursor"); rawCursor != "" {
+		if err := helpers.DecodeCursor(rawCursor, &cursor); err != nil || cursor.Offset < 0 {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "cursor is not valid",
+				Code:  400,
+			})
+			return
+		}
+	}
+
+	rows, err := cfg.Queries.SearchChirps(req.Context(), database.SearchChirpsParams{
+		Query:      searchQuery,
+		PageLimit:  limit + 1,
+		PageOffset: cursor.Offset,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to search chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	page := chirpsPage{}
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		next, err := helpers.EncodeCursor(searchCursor{Offset: cursor.Offset + limit})
+		if err != nil {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "Couldn't make cursor",
+				Code:  500,
+			})
+			return
+		}
+		page.NextCursor = &next
+	}
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, database.Chirp{
+			ID:        row.ID,
+			Body:      row.Body,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			UserID:    row.UserID,
+			ParentID:  row.ParentID,
+			DeletedAt: row.DeletedAt,
+			RepostOf:  row.RepostOf,
+			QuoteOf:   row.QuoteOf,
+		})
+	}
+	page.Chirps, err = cfg.chirpResponses(req.Context(), chirps, viewerID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	data, err := json.Marshal(page)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
@@ -142,6 +541,8 @@ func (cfg *ApiConfig) GetChirpsHandler(w http.ResponseWriter, req *http.Request)
 }
 
 func (cfg *ApiConfig) GetChirpHandler(w http.ResponseWriter, req *http.Request) {
+	viewerID := optionalRequestUserID(req)
+
 	id, err := uuid.Parse(req.PathValue("chirpID"))
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
@@ -160,8 +561,26 @@ func (cfg *ApiConfig) GetChirpHandler(w http.ResponseWriter, req *http.Request)
 		})
 		return
 	}
+	if chirp.DeletedAt.Valid {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("chirp %v is deleted", id),
+			Msg:   "Chirp was deleted",
+			Code:  404,
+		})
+		return
+	}
 
-	data, err := json.Marshal(chirp)
+	res, err := cfg.chirpResponse(req.Context(), chirp, viewerID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirp",
+			Code:  500,
+		})
+		return
+	}
+
+	data, err := json.Marshal(res)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
@@ -186,21 +605,90 @@ func (cfg *ApiConfig) DeleteChirpHandler(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	token, _ := auth.GetBearerToken(req.Header)
-	userID, _ := auth.ValidateJWT(token, cfg.SecretKey)
+	userID := requestUserID(req)
 
-	err = cfg.Queries.DeleteChirpById(req.Context(), database.DeleteChirpByIdParams{
-		ID:     chirpID,
-		UserID: userID,
-	})
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
 	if err != nil {
 		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
 			Error: err,
-			Msg:   "Couldn't delete the user",
+			Msg:   "Couldn't delete chirp",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	// The row lock also blocks replies from being inserted until we are
+	// done, so the replies check below can't go stale.
+	chirp, err := qtx.GetChirpByIdForUpdate(req.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && chirp.DeletedAt.Valid) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("no chirp with that id"),
+			Msg:   "Chirp not found",
+			Code:  404,
+		})
+		return
+	}
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't delete chirp",
+			Code:  500,
+		})
+		return
+	}
+	if chirp.UserID != userID {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("chirp belongs to another user"),
+			Msg:   "You can only delete your own chirps",
 			Code:  403,
 		})
 		return
 	}
 
+	hasReplies, err := qtx.ChirpHasReplies(req.Context(), chirpID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't check chirp replies",
+			Code:  500,
+		})
+		return
+	}
+
+	// Chirps with replies are blanked out instead of removed so the
+	// thread below them keeps its shape.
+	var deleted int64
+	if hasReplies {
+		deleted, err = qtx.SoftDeleteChirpById(req.Context(), database.SoftDeleteChirpByIdParams{
+			ID:     chirpID,
+			UserID: userID,
+			DeletedAt: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		})
+	} else {
+		deleted, err = qtx.DeleteChirpById(req.Context(), database.DeleteChirpByIdParams{
+			ID:     chirpID,
+			UserID: userID,
+		})
+	}
+	if err == nil && deleted == 0 {
+		err = errors.New("locked chirp was not deleted")
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't delete chirp",
+			Code:  500,
+		})
+		return
+	}
+
 	w.WriteHeader(204)
 }
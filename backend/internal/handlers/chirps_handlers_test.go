@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSearchChirpsEmbedsQuotedChirp(t *testing.T) {
+	cfg, h := newTestServer(t)
+	alice := signUp(t, cfg, h, "alice")
+	bob := signUp(t, cfg, h, "bob")
+
+	rec := doRequest(t, h, "POST", "/api/chirps", alice.Token, map[string]string{
+		"body": "Kayaking on the lake this morning",
+	})
+	if rec.Code != 201 {
+		t.Fatalf("creating chirp: %d %s", rec.Code, rec.Body)
+	}
+	original := chirpResponse{}
+	decodeResponse(t, rec, &original)
+
+	rec = doRequest(t, h, "POST", "/api/chirps", bob.Token, map[string]any{
+		"body":     "Jealous of your weekend",
+		"quote_of": original.ID,
+	})
+	if rec.Code != 201 {
+		t.Fatalf("creating quote: %d %s", rec.Code, rec.Body)
+	}
+	quote := chirpResponse{}
+	decodeResponse(t, rec, &quote)
+
+	rec = doRequest(t, h, "GET", "/api/chirps/search?q="+url.QueryEscape("jealous weekend"), "", nil)
+	if rec.Code != 200 {
+		t.Fatalf("searching: %d %s", rec.Code, rec.Body)
+	}
+	page := chirpsPage{}
+	decodeResponse(t, rec, &page)
+	if len(page.Chirps) != 1 {
+		t.Fatalf("got %d results, want 1: %s", len(page.Chirps), rec.Body)
+	}
+	got := page.Chirps[0]
+	if got.ID != quote.ID {
+		t.Fatalf("got chirp %v, want the quote %v", got.ID, quote.ID)
+	}
+	if got.QuoteOf == nil {
+		t.Fatalf("quote_of is not embedded: %s", rec.Body)
+	}
+	if got.QuoteOf.ID != original.ID || got.QuoteOf.Body != original.Body {
+		t.Errorf("quote_of = %v %q, want %v %q", got.QuoteOf.ID, got.QuoteOf.Body, original.ID, original.Body)
+	}
+	if got.RepostOf != nil {
+		t.Errorf("repost_of = %v, want nil", got.RepostOf.ID)
+	}
+}
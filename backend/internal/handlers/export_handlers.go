@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+type exportProfile struct {
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarURL   string    `json:"avatar_url"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type exportChirp struct {
+	ID        uuid.UUID  `json:"id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	RepostOf  *uuid.UUID `json:"repost_of"`
+	QuoteOf   *uuid.UUID `json:"quote_of"`
+}
+
+type exportSession struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+type accountExport struct {
+	ExportedAt time.Time       `json:"exported_at"`
+	Profile    exportProfile   `json:"profile"`
+	Chirps     []exportChirp   `json:"chirps"`
+	Sessions   []exportSession `json:"sessions"`
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func (cfg *ApiConfig) ExportAccountHandler(w http.ResponseWriter, req *http.Request) {
+	userID := requestUserID(req)
+
+	user, err := cfg.Queries.GetUserById(req.Context(), userID)
+	if err == nil && user.DeletedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		code := 500
+		msg := "Couldn't get user"
+		if errors.Is(err, sql.ErrNoRows) {
+			code = 404
+			msg = "User not found"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+	chirps, err := cfg.Queries.GetChirpsByUser(req.Context(), userID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get chirps",
+			Code:  500,
+		})
+		return
+	}
+	sessions, err := cfg.Queries.GetSessionsForUser(req.Context(), userID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get sessions",
+			Code:  500,
+		})
+		return
+	}
+
+	export := accountExport{
+		ExportedAt: time.Now(),
+		Profile: exportProfile{
+			ID:          user.ID,
+			Email:       user.Email,
+			Handle:      user.Handle,
+			DisplayName: user.DisplayName,
+			Bio:         user.Bio,
+			AvatarURL:   user.AvatarUrl,
+			IsChirpyRed: user.IsChirpyRed.Bool,
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+		},
+		Chirps:   make([]exportChirp, 0, len(chirps)),
+		Sessions: make([]exportSession, 0, len(sessions)),
+	}
+	for _, chirp := range chirps {
+		export.Chirps = append(export.Chirps, exportChirp{
+			ID:        chirp.ID,
+			Body:      chirp.Body,
+			CreatedAt: chirp.CreatedAt,
+			UpdatedAt: chirp.UpdatedAt,
+			ParentID:  nullUUIDPtr(chirp.ParentID),
+			RepostOf:  nullUUIDPtr(chirp.RepostOf),
+			QuoteOf:   nullUUIDPtr(chirp.QuoteOf),
+		})
+	}
+	for _, session := range sessions {
+		exported := exportSession{
+			ID:         session.ID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IpAddress,
+		}
+		if session.RevokedAt.Valid {
+			exported.RevokedAt = &session.RevokedAt.Time
+		}
+		export.Sessions = append(export.Sessions, exported)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="chirpy-export-%s.json"`, user.ID))
+	w.WriteHeader(200)
+	w.Write(data)
+}
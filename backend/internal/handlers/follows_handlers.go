@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+type followEntry struct {
+	ID         uuid.UUID `json:"id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followsPage struct {
+	Users      []followEntry `json:"users"`
+	NextCursor *string       `json:"next_cursor"`
+}
+
+func (cfg *ApiConfig) FollowUserHandler(w http.ResponseWriter, req *http.Request) {
+	followeeID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "User id is not valid",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	if followeeID == userID {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("self follow"),
+			Msg:   "You can't follow yourself",
+			Code:  400,
+		})
+		return
+	}
+
+	followee, err := cfg.Queries.GetUserById(req.Context(), followeeID)
+	if err == nil && followee.DeletedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		code := 500
+		msg := "Couldn't get user"
+		if errors.Is(err, sql.ErrNoRows) {
+			code = 404
+			msg = "User not found"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	err = cfg.Queries.FollowUser(req.Context(), database.FollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't follow the user",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *ApiConfig) UnfollowUserHandler(w http.ResponseWriter, req *http.Request) {
+	followeeID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "User id is not valid",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	err = cfg.Queries.UnfollowUser(req.Context(), database.UnfollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't unfollow the user",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *ApiConfig) GetFollowersHandler(w http.ResponseWriter, req *http.Request) {
+	cfg.getFollows(w, req, func(params database.GetFollowersParams) ([]followEntry, error) {
+		rows, err := cfg.Queries.GetFollowers(req.Context(), params)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]followEntry, 0, len(rows))
+		for _, row := range rows {
+			entries = append(entries, followEntry(row))
+		}
+		return entries, nil
+	})
+}
+
+func (cfg *ApiConfig) GetFollowingHandler(w http.ResponseWriter, req *http.Request) {
+	cfg.getFollows(w, req, func(params database.GetFollowersParams) ([]followEntry, error) {
+		rows, err := cfg.Queries.GetFollowing(req.Context(), database.GetFollowingParams(params))
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]followEntry, 0, len(rows))
+		for _, row := range rows {
+			entries = append(entries, followEntry(row))
+		}
+		return entries, nil
+	})
+}
+
+func (cfg *ApiConfig) getFollows(w http.ResponseWriter, req *http.Request, list func(database.GetFollowersParams) ([]followEntry, error)) {
+	userID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "User id is not valid",
+			Code:  400,
+		})
+		return
+	}
+
+	query := req.URL.Query()
+	limit, err := helpers.ParsePageLimit(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+	cursorCreatedAt, cursorID, err := parseKeysetCursor(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	entries, err := list(database.GetFollowersParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get users",
+			Code:  500,
+		})
+		return
+	}
+
+	page := followsPage{
+		Users: entries,
+	}
+	if len(entries) > int(limit) {
+		page.Users = entries[:limit]
+		last := page.Users[len(page.Users)-1]
+		next, err := helpers.EncodeCursor(keysetCursor{
+			CreatedAt: last.FollowedAt,
+			ID:        last.ID,
+		})
+		if err != nil {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "Couldn't make cursor",
+				Code:  500,
+			})
+			return
+		}
+		page.NextCursor = &next
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshaling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+// GetUserRelationHandler serves GET /api/users/{id}/{relation}. A single
+// wildcard pattern lets literal routes such as /api/users/by-handle/{handle}
+// take precedence; two {id}/followers style patterns would conflict with them.
+func (cfg *ApiConfig) GetUserRelationHandler(w http.ResponseWriter, req *http.Request) {
+	switch req.PathValue("relation") {
+	case "followers":
+		cfg.GetFollowersHandler(w, req)
+	case "following":
+		cfg.GetFollowingHandler(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
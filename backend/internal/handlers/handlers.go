@@ -1,21 +1,35 @@
 package handlers
 
 import (
+	"database/sql"
 	"fmt"
 	"net/http"
 	"os"
 	"sync/atomic"
+	"time"
 
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
 	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/mailer"
+	"github.com/ShkolZ/chirpy/backend/internal/moderation"
 
 	_ "github.com/lib/pq"
 )
 
 type ApiConfig struct {
-	FileserverHits atomic.Int32
-	Queries        *database.Queries
-	SecretKey      string
-	PolkaKey       string
+	FileserverHits  atomic.Int32
+	DB              *sql.DB
+	Queries         *database.Queries
+	SecretKey       string
+	PolkaKey        string
+	AdminKey        string
+	ChirpEditWindow time.Duration
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	DenyList        *auth.DenyList
+	Keys            *auth.KeySet
+	Moderation      moderation.Filter
+	Mailer          mailer.Mailer
 }
 
 func (cfg *ApiConfig) HealthzHandler(w http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/mailer"
+	"github.com/ShkolZ/chirpy/backend/internal/moderation"
+	"github.com/google/uuid"
+)
+
+const testAdminKey = "test-admin-key"
+
+// testMailer keeps every message instead of sending it.
+type testMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// openTestDB gives the test a schema of its own in the database at
+// TEST_DB_URL with every migration applied. The test is skipped when
+// TEST_DB_URL is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Logf("Couldn't drop schema %s: %v", schema, err)
+		}
+	})
+
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := filepath.Glob("../../sql/schema/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range migrations {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		if _, err := db.Exec(up); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return db
+}
+
+// newTestServer returns a config backed by a fresh test database and a mux
+// with the routes the tests use, registered the same way main does.
+func newTestServer(t *testing.T) (*ApiConfig, http.Handler) {
+	t.Helper()
+	db := openTestDB(t)
+	keys, err := auth.LoadKeySet(t.TempDir(), auth.AlgEdDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ApiConfig{
+		DB:              db,
+		Queries:         database.New(db),
+		AdminKey:        testAdminKey,
+		ChirpEditWindow: 15 * time.Minute,
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 24 * time.Hour,
+		DenyList:        auth.NewDenyList(),
+		Keys:            keys,
+		Moderation:      moderation.NewWordFilter(nil, moderation.ActionMask),
+		Mailer:          &testMailer{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/moderation/words", cfg.AdminMiddleware(cfg.GetModerationWordsHandler))
+	mux.HandleFunc("GET /api/chirps/search", cfg.OptionalAuth(cfg.SearchChirpsHandler, auth.ScopeChirpsRead))
+	mux.HandleFunc("POST /api/users", cfg.CreateUserHandler)
+	mux.HandleFunc("POST /api/chirps", cfg.RequireAuth(cfg.CreateChirpHandler, auth.ScopeChirpsWrite))
+	mux.HandleFunc("POST /api/login", cfg.LoginHandler)
+	mux.HandleFunc("POST /api/refresh", cfg.RefreshTokenHandler)
+	mux.HandleFunc("PUT /api/users", cfg.RequireAuth(cfg.UpdateCredentialsHandler))
+	mux.HandleFunc("PUT /api/users/me/password", cfg.RequireAuth(cfg.ChangePasswordHandler))
+	mux.HandleFunc("PUT /admin/moderation/words", cfg.AdminMiddleware(cfg.UpdateModerationWordsHandler))
+	return cfg, mux
+}
+
+// doRequest sends body as JSON, with token as bearer token when it is set.
+func doRequest(t *testing.T, h http.Handler, method, target, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	authorization := ""
+	if token != "" {
+		authorization = "Bearer " + token
+	}
+	return doRequestWithAuthorization(t, h, method, target, authorization, body)
+}
+
+// doAdminRequest is doRequest with the admin key.
+func doAdminRequest(t *testing.T, h http.Handler, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	return doRequestWithAuthorization(t, h, method, target, "ApiKey "+testAdminKey, body)
+}
+
+func doRequestWithAuthorization(t *testing.T, h http.Handler, method, target, authorization string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, target, &reqBody)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+}
+
+type testUser struct {
+	ID           uuid.UUID
+	Email        string
+	Password     string
+	Token        string
+	RefreshToken string
+}
+
+// signUp creates a verified user and logs them in.
+func signUp(t *testing.T, cfg *ApiConfig, h http.Handler, name string) testUser {
+	t.Helper()
+	user := testUser{
+		Email:    fmt.Sprintf("%s-%s@example.com", name, uuid.NewString()[:8]),
+		Password: "correct horse battery staple",
+	}
+	rec := doRequest(t, h, "POST", "/api/users", "", map[string]string{
+		"email":    user.Email,
+		"password": user.Password,
+	})
+	if rec.Code != 201 {
+		t.Fatalf("creating user: %d %s", rec.Code, rec.Body)
+	}
+	created := database.User{}
+	decodeResponse(t, rec, &created)
+	user.ID = created.ID
+	if _, err := cfg.Queries.SetEmailVerified(context.Background(), database.SetEmailVerifiedParams{
+		VerifiedAt: time.Now(),
+		ID:         user.ID,
+		Email:      user.Email,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	user.Token, user.RefreshToken = logIn(t, h, user.Email, user.Password)
+	return user
+}
+
+func logIn(t *testing.T, h http.Handler, email, password string) (token, refreshToken string) {
+	t.Helper()
+	rec := doRequest(t, h, "POST", "/api/login", "", map[string]string{
+		"email":    email,
+		"password": password,
+	})
+	if rec.Code != 200 {
+		t.Fatalf("logging in: %d %s", rec.Code, rec.Body)
+	}
+	res := struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}{}
+	decodeResponse(t, rec, &res)
+	return res.Token, res.RefreshToken
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+)
+
+func (cfg *ApiConfig) JWKSHandler(w http.ResponseWriter, req *http.Request) {
+	data, err := json.Marshal(cfg.Keys.JWKS())
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// New keys are published well before they sign anything, see
+	// auth.JWKSMaxAge.
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(auth.JWKSMaxAge.Seconds())))
+	w.WriteHeader(200)
+	w.Write(data)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+func (cfg *ApiConfig) LikeChirpHandler(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	chirp, err := cfg.Queries.GetChirpById(req.Context(), chirpID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error getting chirp by id",
+			Code:  500,
+		})
+		return
+	}
+	if err != nil || chirp.DeletedAt.Valid {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("chirp %v not found", chirpID),
+			Msg:   "Chirp not found",
+			Code:  404,
+		})
+		return
+	}
+
+	err = cfg.Queries.LikeChirp(req.Context(), database.LikeChirpParams{
+		ChirpID:   chirpID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't like the chirp",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *ApiConfig) UnlikeChirpHandler(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	err = cfg.Queries.UnlikeChirp(req.Context(), database.UnlikeChirpParams{
+		ChirpID: chirpID,
+		UserID:  userID,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't unlike the chirp",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
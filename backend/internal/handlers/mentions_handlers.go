@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+type mentionResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Handle string    `json:"handle"`
+}
+
+// setChirpMentions replaces the mentions stored for a chirp. Handles that
+// don't belong to anyone are skipped and stay plain text in the body.
+func setChirpMentions(ctx context.Context, q *database.Queries, chirp database.Chirp, mentioned []string) error {
+	if err := q.DeleteChirpMentions(ctx, chirp.ID); err != nil {
+		return err
+	}
+	if len(mentioned) == 0 {
+		return nil
+	}
+	users, err := q.GetUsersByHandles(ctx, mentioned)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		err := q.AddChirpMention(ctx, database.AddChirpMentionParams{
+			ChirpID:   chirp.ID,
+			UserID:    user.ID,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *ApiConfig) GetMyMentionsHandler(w http.ResponseWriter, req *http.Request) {
+	userID := requestUserID(req)
+
+	query := req.URL.Query()
+	limit, err := helpers.ParsePageLimit(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+	cursorCreatedAt, cursorID, err := parseKeysetCursor(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	chirps, err := cfg.Queries.GetMentionsOfUser(req.Context(), database.GetMentionsOfUserParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get mentions",
+			Code:  500,
+		})
+		return
+	}
+
+	page, err := cfg.newChirpsPage(req.Context(), chirps, limit, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshaling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
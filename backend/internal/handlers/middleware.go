@@ -1,10 +1,23 @@
 package handlers
 
 import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
 )
 
+type contextKey int
+
+const principalKey contextKey = iota
+
 func (cfg *ApiConfig) LoggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		log.Printf("%s %s", req.Method, req.URL.Path)
@@ -19,3 +32,155 @@ func (cfg *ApiConfig) MetricsIncMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, req)
 	})
 }
+
+// AdminMiddleware only lets requests through that carry
+// "Authorization: ApiKey <ADMIN_KEY>".
+func (cfg *ApiConfig) AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got, err := auth.GetApiKey(req.Header)
+		if err == nil && (cfg.AdminKey == "" || subtle.ConstantTimeCompare([]byte(got), []byte(cfg.AdminKey)) != 1) {
+			err = errors.New("wrong admin key")
+		}
+		if err != nil {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "Access denied",
+				Code:  403,
+			})
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// principal is whoever a request is authenticated as. Scopes is nil for
+// login sessions, which may do anything; Claims is only set for them.
+type principal struct {
+	UserID uuid.UUID
+	Claims auth.AccessClaims
+	Scopes []string
+}
+
+// RequireAuth validates the bearer token once and stores the caller in the
+// request context. Requests without a valid token get a 401. Personal access
+// tokens are only let through when scopes are given and they hold all of
+// them.
+func (cfg *ApiConfig) RequireAuth(next http.HandlerFunc, scopes ...string) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cfg.authenticate(w, req, next, false, scopes)
+	})
+}
+
+// OptionalAuth lets anonymous requests through, but a token that is sent
+// still has to be valid and hold the scopes.
+func (cfg *ApiConfig) OptionalAuth(next http.HandlerFunc, scopes ...string) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cfg.authenticate(w, req, next, true, scopes)
+	})
+}
+
+func (cfg *ApiConfig) authenticate(w http.ResponseWriter, req *http.Request, next http.HandlerFunc, optional bool, scopes []string) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if errors.Is(err, auth.ErrNoAuthHeader) {
+		if optional {
+			next.ServeHTTP(w, req)
+			return
+		}
+		respondUnauthorized(w, req, err, "")
+		return
+	}
+	if err != nil {
+		respondUnauthorized(w, req, err, "invalid_request")
+		return
+	}
+
+	var caller principal
+	if auth.IsPersonalAccessToken(tokenString) {
+		caller, err = cfg.personalAccessTokenPrincipal(req.Context(), tokenString)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "Couldn't check access token",
+				Code:  500,
+			})
+			return
+		}
+	} else {
+		caller.Claims, err = cfg.validateAccessToken(tokenString)
+		caller.UserID = caller.Claims.UserID
+	}
+	if err != nil {
+		respondUnauthorized(w, req, err, "invalid_token")
+		return
+	}
+
+	// Tokens outlive the account they were issued for, so a deleted or
+	// anonymised user is turned away here rather than in every handler.
+	user, err := cfg.Queries.GetUserById(req.Context(), caller.UserID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.DeletedAt.Valid) {
+		respondUnauthorized(w, req, errors.New("account was deleted"), "invalid_token")
+		return
+	}
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't check access token",
+			Code:  500,
+		})
+		return
+	}
+
+	if caller.Scopes != nil && (len(scopes) == 0 || !auth.HasScopes(caller.Scopes, scopes)) {
+		challenge := `Bearer realm="chirpy", error="insufficient_scope"`
+		if len(scopes) > 0 {
+			challenge += `, scope="` + strings.Join(scopes, " ") + `"`
+		}
+		w.Header().Set("WWW-Authenticate", challenge)
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("token lacks required scopes"),
+			Msg:   "Token doesn't have the scope needed for this request",
+			Code:  403,
+		})
+		return
+	}
+
+	ctx := context.WithValue(req.Context(), principalKey, caller)
+	next.ServeHTTP(w, req.WithContext(ctx))
+}
+
+// respondUnauthorized sends the same 401 for every auth failure. code is the
+// RFC 6750 error code and is left out when no credentials were sent.
+func respondUnauthorized(w http.ResponseWriter, req *http.Request, err error, code string) {
+	challenge := `Bearer realm="chirpy"`
+	if code != "" {
+		challenge += `, error="` + code + `"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+		Error: err,
+		Msg:   "Missing or invalid access token",
+		Code:  401,
+	})
+}
+
+// requestClaims returns the access token claims of a login session. It
+// must only be used behind RequireAuth without scopes.
+func requestClaims(req *http.Request) auth.AccessClaims {
+	caller, _ := req.Context().Value(principalKey).(principal)
+	return caller.Claims
+}
+
+func requestUserID(req *http.Request) uuid.UUID {
+	caller, _ := req.Context().Value(principalKey).(principal)
+	return caller.UserID
+}
+
+// optionalRequestUserID returns the caller's id behind OptionalAuth, or an
+// invalid NullUUID for anonymous requests.
+func optionalRequestUserID(req *http.Request) uuid.NullUUID {
+	caller, ok := req.Context().Value(principalKey).(principal)
+	if !ok {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: caller.UserID, Valid: true}
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/ShkolZ/chirpy/backend/internal/moderation"
+	"github.com/google/uuid"
+)
+
+type moderationWordsResponse struct {
+	Words  []string          `json:"words"`
+	Action moderation.Action `json:"action"`
+}
+
+type moderationFlagResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Matches   []string  `json:"matches"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// flagChirp queues a chirp for review. The chirp is already stored, so a
+// failure here is only logged.
+func (cfg *ApiConfig) flagChirp(ctx context.Context, chirpID uuid.UUID, matches []string) {
+	if len(matches) == 0 {
+		return
+	}
+	err := cfg.Queries.CreateModerationFlag(ctx, database.CreateModerationFlagParams{
+		ID:        uuid.New(),
+		ChirpID:   chirpID,
+		Matches:   matches,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Couldn't flag chirp %v: %v", chirpID, err)
+	}
+}
+
+// StoredModerationWords returns the word list admins set through
+// UpdateModerationWordsHandler. configured is false until they have set one,
+// an empty list that was set on purpose comes back as configured.
+func StoredModerationWords(ctx context.Context, q *database.Queries) (words []string, configured bool, err error) {
+	if _, err := q.GetModerationWordListUpdatedAt(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	words, err = q.GetModerationWords(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return words, true, nil
+}
+
+// moderationWordList returns the configured filter's word list, answering
+// 501 when the filter doesn't have one admins can edit.
+func (cfg *ApiConfig) moderationWordList(w http.ResponseWriter, req *http.Request) (moderation.WordList, bool) {
+	list, ok := cfg.Moderation.(moderation.WordList)
+	if !ok {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("moderation filter has no word list"),
+			Msg:   "The moderation filter in use has no editable word list",
+			Code:  501,
+		})
+	}
+	return list, ok
+}
+
+func (cfg *ApiConfig) GetModerationWordsHandler(w http.ResponseWriter, req *http.Request) {
+	list, ok := cfg.moderationWordList(w, req)
+	if !ok {
+		return
+	}
+
+	data, err := json.Marshal(moderationWordsResponse{
+		Words:  list.Words(),
+		Action: cfg.Moderation.Action(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) UpdateModerationWordsHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Words []string `json:"words"`
+	}
+
+	list, ok := cfg.moderationWordList(w, req)
+	if !ok {
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+
+	words := []string{}
+	for _, word := range params.Words {
+		if normalized := moderation.Normalize(word); normalized != "" {
+			words = append(words, normalized)
+		}
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't update moderation words",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	if err := qtx.DeleteModerationWords(req.Context()); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't update moderation words",
+			Code:  500,
+		})
+		return
+	}
+	for _, word := range words {
+		err := qtx.AddModerationWord(req.Context(), database.AddModerationWordParams{
+			Word:      word,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: err,
+				Msg:   "Couldn't update moderation words",
+				Code:  500,
+			})
+			return
+		}
+	}
+	if err := qtx.SetModerationWordListUpdatedAt(req.Context(), time.Now()); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't update moderation words",
+			Code:  500,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't update moderation words",
+			Code:  500,
+		})
+		return
+	}
+	list.SetWords(words)
+
+	data, _ := json.Marshal(moderationWordsResponse{
+		Words:  list.Words(),
+		Action: cfg.Moderation.Action(),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) GetModerationFlagsHandler(w http.ResponseWriter, req *http.Request) {
+	flags, err := cfg.Queries.GetOpenModerationFlags(req.Context())
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get moderation flags",
+			Code:  500,
+		})
+		return
+	}
+
+	res := make([]moderationFlagResponse, 0, len(flags))
+	for _, flag := range flags {
+		res = append(res, moderationFlagResponse{
+			ID:        flag.ID,
+			ChirpID:   flag.ChirpID,
+			Matches:   flag.Matches,
+			CreatedAt: flag.CreatedAt,
+		})
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) ResolveModerationFlagHandler(w http.ResponseWriter, req *http.Request) {
+	flagID, err := uuid.Parse(req.PathValue("flagID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	resolved, err := cfg.Queries.ResolveModerationFlag(req.Context(), database.ResolveModerationFlagParams{
+		ID: flagID,
+		ResolvedAt: sql.NullTime{
+			Time:  time.Now(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't resolve moderation flag",
+			Code:  500,
+		})
+		return
+	}
+	if resolved == 0 {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("no open flag with that id"),
+			Msg:   "Moderation flag not found",
+			Code:  404,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
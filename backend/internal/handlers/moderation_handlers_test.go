@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestModerationWordsRoundTrip(t *testing.T) {
+	cfg, h := newTestServer(t)
+	ctx := context.Background()
+
+	words, configured, err := StoredModerationWords(ctx, cfg.Queries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configured || len(words) != 0 {
+		t.Fatalf("fresh database: got %q, configured %v, want nothing configured", words, configured)
+	}
+
+	tests := []struct {
+		name  string
+		words []string
+		want  []string
+	}{
+		{"set", []string{"Kerfuffle", "sharbert", "  "}, []string{"kerfuffle", "sharbert"}},
+		{"cleared", []string{}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doAdminRequest(t, h, "PUT", "/admin/moderation/words", map[string][]string{
+				"words": tt.words,
+			})
+			if rec.Code != 200 {
+				t.Fatalf("updating words: %d %s", rec.Code, rec.Body)
+			}
+
+			rec = doAdminRequest(t, h, "GET", "/admin/moderation/words", nil)
+			if rec.Code != 200 {
+				t.Fatalf("getting words: %d %s", rec.Code, rec.Body)
+			}
+			res := moderationWordsResponse{}
+			decodeResponse(t, rec, &res)
+			if !slices.Equal(res.Words, tt.want) {
+				t.Errorf("GET words = %q, want %q", res.Words, tt.want)
+			}
+
+			// What the next start would load instead of the defaults.
+			words, configured, err := StoredModerationWords(ctx, cfg.Queries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !configured {
+				t.Fatal("word list is not marked as configured")
+			}
+			if !slices.Equal(words, tt.want) {
+				t.Errorf("stored words = %q, want %q", words, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/ShkolZ/chirpy/backend/internal/mailer"
+)
+
+const passwordResetTTL = time.Hour
+
+const passwordResetSendTimeout = 30 * time.Second
+
+// ForgotPasswordHandler always answers 202 straight away and looks up the
+// account in the background, so neither the status nor the response time
+// tells callers whether an account exists.
+func (cfg *ApiConfig) ForgotPasswordHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Email string `json:"email"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), passwordResetSendTimeout)
+	go func() {
+		defer cancel()
+		if err := cfg.sendPasswordReset(ctx, params.Email); err != nil {
+			log.Printf("Couldn't send password reset: %v", err)
+		}
+	}()
+
+	w.WriteHeader(202)
+}
+
+// sendPasswordReset mails a reset token if email belongs to a live account.
+func (cfg *ApiConfig) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := cfg.Queries.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt.Valid {
+		return nil
+	}
+
+	token, err := auth.MakeSecretToken()
+	if err != nil {
+		return err
+	}
+
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	if err := qtx.DeletePasswordResetsForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	err = qtx.CreatePasswordReset(ctx, database.CreatePasswordResetParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if err := cfg.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body:    fmt.Sprintf("Use this token to reset your password: %s\nIt expires in %v. If you didn't ask for a reset you can ignore this email.", token, passwordResetTTL),
+	}); err != nil {
+		return fmt.Errorf("user %v: %w", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPasswordHandler sets a new password and revokes every refresh token of
+// the user, so a stolen session doesn't survive the reset.
+func (cfg *ApiConfig) ResetPasswordHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+	if params.NewPassword == "" {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("empty password"),
+			Msg:   "New password must not be empty",
+			Code:  400,
+		})
+		return
+	}
+
+	passHash, err := auth.HashPassword(params.NewPassword)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't hash the password",
+			Code:  500,
+		})
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't reset password",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	tokenHash := auth.HashToken(params.Token)
+	reset, err := qtx.GetPasswordReset(req.Context(), tokenHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't reset password",
+			Code:  500,
+		})
+		return
+	}
+	if err != nil || reset.UsedAt.Valid || time.Now().After(reset.ExpiresAt) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("unknown, used or expired password reset token"),
+			Msg:   "Token is invalid or expired",
+			Code:  400,
+		})
+		return
+	}
+
+	now := time.Now()
+	used, err := qtx.UsePasswordReset(req.Context(), database.UsePasswordResetParams{
+		TokenHash: tokenHash,
+		UsedAt:    sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't reset password",
+			Code:  500,
+		})
+		return
+	}
+	if used == 0 {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("password reset token was already used"),
+			Msg:   "Token is invalid or expired",
+			Code:  400,
+		})
+		return
+	}
+	if _, err := qtx.UpdateUserPassword(req.Context(), database.UpdateUserPasswordParams{
+		ID:        reset.UserID,
+		Password:  passHash,
+		UpdatedAt: now,
+	}); err != nil {
+		respondWithUserUpdateError(w, req, err)
+		return
+	}
+	if err := revokeAllSessions(req.Context(), qtx, reset.UserID); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke sessions",
+			Code:  500,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't reset password",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+var errChirpNotFound = errors.New("chirp not found")
+
+// resolveRepost returns the chirp behind id, following rechirps to the
+// chirp they repost so shares never chain.
+func (cfg *ApiConfig) resolveRepost(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.Queries.GetChirpById(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Chirp{}, errChirpNotFound
+	}
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.RepostOf.Valid {
+		return cfg.resolveRepost(ctx, chirp.RepostOf.UUID)
+	}
+	return chirp, nil
+}
+
+// shareableChirp returns the chirp a rechirp or quote should point at.
+func (cfg *ApiConfig) shareableChirp(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.resolveRepost(ctx, id)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.DeletedAt.Valid {
+		return database.Chirp{}, errChirpNotFound
+	}
+	return chirp, nil
+}
+
+func (cfg *ApiConfig) RechirpHandler(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+	if cfg.respondIfUnverified(w, req, userID) {
+		return
+	}
+
+	original, err := cfg.shareableChirp(req.Context(), chirpID)
+	if err != nil {
+		code := 500
+		msg := "Some error getting chirp by id"
+		if errors.Is(err, errChirpNotFound) {
+			code = 404
+			msg = "Chirp you are rechirping doesn't exist"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+	repostOf := uuid.NullUUID{UUID: original.ID, Valid: true}
+
+	code := 200
+	rechirp, err := cfg.Queries.GetRechirp(req.Context(), database.GetRechirpParams{
+		UserID:   userID,
+		RepostOf: repostOf,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		code = 201
+		rechirp, err = cfg.Queries.CreateRechirp(req.Context(), database.CreateRechirpParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    userID,
+			RepostOf:  repostOf,
+		})
+	}
+	if err != nil {
+		code := 500
+		msg := "Some error creating rechirp"
+		if helpers.IsUniqueViolation(err) {
+			code = 409
+			msg = "Chirp is already rechirped"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	res, err := cfg.chirpResponse(req.Context(), rechirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirp",
+			Code:  500,
+		})
+		return
+	}
+
+	data, _ := json.Marshal(res)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) UndoRechirpHandler(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	// Resolved like RechirpHandler does, but a rechirp of a chirp that was
+	// deleted since can still be undone.
+	original, err := cfg.resolveRepost(req.Context(), chirpID)
+	if err != nil {
+		code := 500
+		msg := "Some error getting chirp by id"
+		if errors.Is(err, errChirpNotFound) {
+			code = 404
+			msg = "Chirp doesn't exist"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	err = cfg.Queries.DeleteRechirp(req.Context(), database.DeleteRechirpParams{
+		UserID:   userID,
+		RepostOf: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't undo the rechirp",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// userProfileResponse is the public view of a user. It must never carry the
+// email address.
+type userProfileResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarURL   string    `json:"avatar_url"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newUserProfileResponse(user database.User) userProfileResponse {
+	return userProfileResponse{
+		ID:          user.ID,
+		Handle:      user.Handle,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		IsChirpyRed: user.IsChirpyRed.Bool,
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+type authorSummary struct {
+	ID          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+}
+
+type chirpResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	Body      string            `json:"body"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	UserID    uuid.UUID         `json:"user_id"`
+	Author    *authorSummary    `json:"author"`
+	ParentID  *uuid.UUID        `json:"parent_id"`
+	RepostOf  *chirpResponse    `json:"repost_of"`
+	QuoteOf   *chirpResponse    `json:"quote_of"`
+	Deleted   bool              `json:"deleted,omitempty"`
+	Mentions  []mentionResponse `json:"mentions"`
+	LikeCount int64             `json:"like_count"`
+	LikedByMe *bool             `json:"liked_by_me,omitempty"`
+}
+
+func newChirpResponse(chirp database.Chirp) chirpResponse {
+	res := chirpResponse{
+		ID:        chirp.ID,
+		Body:      chirp.Body,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		UserID:    chirp.UserID,
+		Mentions:  []mentionResponse{},
+		Deleted:   chirp.DeletedAt.Valid,
+	}
+	if chirp.ParentID.Valid {
+		parentID := chirp.ParentID.UUID
+		res.ParentID = &parentID
+	}
+	return res
+}
+
+// chirpResponses converts chirps into responses, embeds the chirps they
+// rechirp or quote and fills in authors, mentions and like counts. liked_by_me is only
+// set when viewerID is valid.
+func (cfg *ApiConfig) chirpResponses(ctx context.Context, chirps []database.Chirp, viewerID uuid.NullUUID) ([]chirpResponse, error) {
+	responses := make([]chirpResponse, 0, len(chirps))
+	if len(chirps) == 0 {
+		return responses, nil
+	}
+
+	known := make(map[uuid.UUID]database.Chirp, len(chirps))
+	for _, chirp := range chirps {
+		known[chirp.ID] = chirp
+	}
+	referenced := []uuid.UUID{}
+	for _, chirp := range chirps {
+		for _, ref := range []uuid.NullUUID{chirp.RepostOf, chirp.QuoteOf} {
+			if _, ok := known[ref.UUID]; ref.Valid && !ok {
+				referenced = append(referenced, ref.UUID)
+			}
+		}
+	}
+	if len(referenced) > 0 {
+		embedded, err := cfg.Queries.GetChirpsByIds(ctx, referenced)
+		if err != nil {
+			return nil, err
+		}
+		for _, chirp := range embedded {
+			known[chirp.ID] = chirp
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(known))
+	for id := range known {
+		ids = append(ids, id)
+	}
+	stats, err := cfg.Queries.GetChirpLikeStats(ctx, database.GetChirpLikeStatsParams{
+		ViewerID: viewerID,
+		ChirpIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	statsByChirp := make(map[uuid.UUID]database.GetChirpLikeStatsRow, len(stats))
+	for _, stat := range stats {
+		statsByChirp[stat.ChirpID] = stat
+	}
+
+	authorIDs := []uuid.UUID{}
+	seenAuthors := map[uuid.UUID]bool{}
+	for _, chirp := range known {
+		if !seenAuthors[chirp.UserID] {
+			seenAuthors[chirp.UserID] = true
+			authorIDs = append(authorIDs, chirp.UserID)
+		}
+	}
+	authors, err := cfg.Queries.GetUsersByIds(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	authorsByID := make(map[uuid.UUID]*authorSummary, len(authors))
+	for _, author := range authors {
+		authorsByID[author.ID] = &authorSummary{
+			ID:          author.ID,
+			Handle:      author.Handle,
+			DisplayName: author.DisplayName,
+			AvatarURL:   author.AvatarUrl,
+		}
+	}
+
+	mentions, err := cfg.Queries.GetChirpMentions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	mentionsByChirp := make(map[uuid.UUID][]mentionResponse, len(mentions))
+	for _, mention := range mentions {
+		mentionsByChirp[mention.ChirpID] = append(mentionsByChirp[mention.ChirpID], mentionResponse{
+			UserID: mention.UserID,
+			Handle: mention.Handle,
+		})
+	}
+
+	build := func(chirp database.Chirp) chirpResponse {
+		res := newChirpResponse(chirp)
+		stat := statsByChirp[chirp.ID]
+		res.Author = authorsByID[chirp.UserID]
+		res.LikeCount = stat.LikeCount
+		if mentioned, ok := mentionsByChirp[chirp.ID]; ok {
+			res.Mentions = mentioned
+		}
+		if viewerID.Valid {
+			likedByMe := stat.LikedByMe
+			res.LikedByMe = &likedByMe
+		}
+		return res
+	}
+	embed := func(ref uuid.NullUUID) *chirpResponse {
+		chirp, ok := known[ref.UUID]
+		if !ref.Valid || !ok {
+			return nil
+		}
+		res := build(chirp)
+		return &res
+	}
+
+	for _, chirp := range chirps {
+		res := build(chirp)
+		res.RepostOf = embed(chirp.RepostOf)
+		res.QuoteOf = embed(chirp.QuoteOf)
+		responses = append(responses, res)
+	}
+	return responses, nil
+}
+
+func (cfg *ApiConfig) chirpResponse(ctx context.Context, chirp database.Chirp, viewerID uuid.NullUUID) (chirpResponse, error) {
+	responses, err := cfg.chirpResponses(ctx, []database.Chirp{chirp}, viewerID)
+	if err != nil {
+		return chirpResponse{}, err
+	}
+	return responses[0], nil
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/chirps"
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+func (cfg *ApiConfig) UpdateChirpHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Body string `json:"body"`
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+	if cfg.respondIfUnverified(w, req, userID) {
+		return
+	}
+
+	validated, err := chirps.Validate(params.Body, cfg.Moderation)
+	if err != nil {
+		respondWithValidationError(w, req, err)
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't update the chirp",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	// Locking the row makes concurrent edits and deletes wait, so every
+	// previous body gets its own revision and a deleted chirp stays deleted.
+	chirp, err := qtx.GetChirpByIdForUpdate(req.Context(), chirpID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error getting chirp by id",
+			Code:  500,
+		})
+		return
+	}
+	if err != nil || chirp.DeletedAt.Valid {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("chirp %v not found", chirpID),
+			Msg:   "Chirp not found",
+			Code:  404,
+		})
+		return
+	}
+	if chirp.UserID != userID {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("user %v is not the author of chirp %v", userID, chirpID),
+			Msg:   "Only the author can edit a chirp",
+			Code:  403,
+		})
+		return
+	}
+	if chirp.RepostOf.Valid {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("chirp %v is a rechirp", chirpID),
+			Msg:   "Rechirps can't be edited",
+			Code:  400,
+		})
+		return
+	}
+	if time.Since(chirp.CreatedAt) > cfg.ChirpEditWindow {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("chirp %v is older than %v", chirpID, cfg.ChirpEditWindow),
+			Msg:   "Chirp can no longer be edited",
+			Code:  403,
+		})
+		return
+	}
+
+	now := time.Now()
+	err = qtx.CreateChirpRevision(req.Context(), database.CreateChirpRevisionParams{
+		ID:         uuid.New(),
+		ChirpID:    chirp.ID,
+		Body:       chirp.Body,
+		CreatedAt:  chirp.UpdatedAt,
+		ReplacedAt: now,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't save chirp revision",
+			Code:  500,
+		})
+		return
+	}
+	chirp, err = qtx.UpdateChirpBody(req.Context(), database.UpdateChirpBodyParams{
+		ID:        chirp.ID,
+		Body:      validated.Body,
+		UpdatedAt: now,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("chirp %v was deleted", chirpID),
+			Msg:   "Chirp not found",
+			Code:  404,
+		})
+		return
+	}
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't update the chirp",
+			Code:  500,
+		})
+		return
+	}
+	if err := setChirpTags(req.Context(), qtx, chirp, validated.Tags); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't save chirp tags",
+			Code:  500,
+		})
+		return
+	}
+	if err := setChirpMentions(req.Context(), qtx, chirp, validated.Mentions); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't save chirp mentions",
+			Code:  500,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't update the chirp",
+			Code:  500,
+		})
+		return
+	}
+	cfg.flagChirp(req.Context(), chirp.ID, validated.Flags)
+
+	res, err := cfg.chirpResponse(req.Context(), chirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirp",
+			Code:  500,
+		})
+		return
+	}
+
+	data, _ := json.Marshal(res)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) GetChirpRevisionsHandler(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	chirp, err := cfg.Queries.GetChirpById(req.Context(), chirpID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error getting chirp by id",
+			Code:  500,
+		})
+		return
+	}
+	if err != nil || chirp.DeletedAt.Valid {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("chirp %v not found", chirpID),
+			Msg:   "Chirp not found",
+			Code:  404,
+		})
+		return
+	}
+
+	revisions, err := cfg.Queries.GetChirpRevisions(req.Context(), chirpID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get revisions",
+			Code:  500,
+		})
+		return
+	}
+	if revisions == nil {
+		revisions = []database.ChirpRevision{}
+	}
+
+	data, err := json.Marshal(revisions)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
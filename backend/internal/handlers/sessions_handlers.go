@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+type sessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+}
+
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// startSession creates a session for a fresh login and returns its first
+// refresh token and its id. The session id doubles as the refresh token
+// family and goes into the access tokens as their sid.
+func startSession(ctx context.Context, q *database.Queries, req *http.Request, userID uuid.UUID, ttl time.Duration) (string, uuid.UUID, error) {
+	session, err := q.CreateSession(ctx, database.CreateSessionParams{
+		ID:         uuid.New(),
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+		LastUsedAt: time.Now(),
+		UserAgent:  req.UserAgent(),
+		IpAddress:  clientIP(req),
+	})
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	refToken, err := createRefreshToken(ctx, q, userID, session.ID, ttl)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	return refToken, session.ID, nil
+}
+
+// revokeSession ends a session and every refresh token in it.
+func revokeSession(ctx context.Context, q *database.Queries, sessionID, userID uuid.UUID) (int64, error) {
+	revoked, err := q.RevokeSession(ctx, database.RevokeSessionParams{
+		RevokedAt: time.Now(),
+		ID:        sessionID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	err = q.RevokeRefreshTokenFamily(ctx, database.RevokeRefreshTokenFamilyParams{
+		FamilyID:  sessionID,
+		UpdatedAt: time.Now(),
+	})
+	return revoked, err
+}
+
+func (cfg *ApiConfig) ListSessionsHandler(w http.ResponseWriter, req *http.Request) {
+	userID := requestUserID(req)
+
+	sessions, err := cfg.Queries.GetActiveSessionsForUser(req.Context(), database.GetActiveSessionsForUserParams{
+		UserID: userID,
+		Now:    time.Now(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get sessions",
+			Code:  500,
+		})
+		return
+	}
+
+	res := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, sessionResponse{
+			ID:         session.ID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IpAddress,
+		})
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) RevokeSessionHandler(w http.ResponseWriter, req *http.Request) {
+	sessionID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Session id is not valid",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke session",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	revoked, err := revokeSession(req.Context(), cfg.Queries.WithTx(tx), sessionID, userID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke session",
+			Code:  500,
+		})
+		return
+	}
+	if revoked == 0 {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("no active session %v for user %v", sessionID, userID),
+			Msg:   "Session not found",
+			Code:  404,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke session",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *ApiConfig) RevokeAllSessionsHandler(w http.ResponseWriter, req *http.Request) {
+	userID := requestUserID(req)
+
+	if err := revokeAllSessions(req.Context(), cfg.Queries, userID); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke sessions",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func revokeAllSessions(ctx context.Context, q *database.Queries, userID uuid.UUID) error {
+	err := q.RevokeSessionsForUser(ctx, database.RevokeSessionsForUserParams{
+		RevokedAt: time.Now(),
+		UserID:    userID,
+	})
+	if err != nil {
+		return err
+	}
+	return q.RevokeRefreshTokensForUser(ctx, database.RevokeRefreshTokensForUserParams{
+		UserID:    userID,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// revokeOtherSessions is revokeAllSessions except for keepID, the caller's
+// own session. A zero keepID keeps none.
+func revokeOtherSessions(ctx context.Context, q *database.Queries, userID, keepID uuid.UUID) error {
+	err := q.RevokeOtherSessionsForUser(ctx, database.RevokeOtherSessionsForUserParams{
+		RevokedAt: time.Now(),
+		UserID:    userID,
+		KeepID:    keepID,
+	})
+	if err != nil {
+		return err
+	}
+	return q.RevokeOtherRefreshTokensForUser(ctx, database.RevokeOtherRefreshTokensForUserParams{
+		UserID:    userID,
+		FamilyID:  keepID,
+		UpdatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+)
+
+type trendingTag struct {
+	Tag        string `json:"tag"`
+	ChirpCount int64  `json:"chirp_count"`
+}
+
+type trendingTagsResponse struct {
+	Since time.Time     `json:"since"`
+	Tags  []trendingTag `json:"tags"`
+}
+
+// setChirpTags replaces the tags stored for a chirp. Tags keep the chirp's
+// creation time so edits don't push old chirps back into trending.
+func setChirpTags(ctx context.Context, q *database.Queries, chirp database.Chirp, tags []string) error {
+	if err := q.DeleteChirpTags(ctx, chirp.ID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		err := q.AddChirpTag(ctx, database.AddChirpTagParams{
+			ChirpID:   chirp.ID,
+			Tag:       tag,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *ApiConfig) GetTagChirpsHandler(w http.ResponseWriter, req *http.Request) {
+	viewerID := optionalRequestUserID(req)
+
+	tag := strings.ToLower(strings.TrimPrefix(req.PathValue("tag"), "#"))
+	if tag == "" {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("empty tag"),
+			Msg:   "Tag must not be empty",
+			Code:  400,
+		})
+		return
+	}
+
+	query := req.URL.Query()
+	limit, err := helpers.ParsePageLimit(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+	cursorCreatedAt, cursorID, err := parseKeysetCursor(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	chirps, err := cfg.Queries.GetChirpsByTag(req.Context(), database.GetChirpsByTagParams{
+		Tag:             tag,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageLimit:       limit + 1,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	page, err := cfg.newChirpsPage(req.Context(), chirps, limit, viewerID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshaling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) GetTrendingTagsHandler(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	limit, err := helpers.ParsePageLimit(query)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	window := defaultTrendingWindow
+	if rawWindow := query.Get("window"); rawWindow != "" {
+		window, err = time.ParseDuration(rawWindow)
+		if err != nil || window <= 0 || window > maxTrendingWindow {
+			helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+				Error: fmt.Errorf("invalid window %q", rawWindow),
+				Msg:   fmt.Sprintf("window must be a duration between 0 and %v, e.g. 6h", maxTrendingWindow),
+				Code:  400,
+			})
+			return
+		}
+	}
+
+	since := time.Now().Add(-window)
+	rows, err := cfg.Queries.GetTrendingTags(req.Context(), database.GetTrendingTagsParams{
+		Since:     since,
+		PageLimit: limit,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get trending tags",
+			Code:  500,
+		})
+		return
+	}
+
+	res := trendingTagsResponse{
+		Since: since,
+		Tags:  make([]trendingTag, 0, len(rows)),
+	}
+	for _, row := range rows {
+		res.Tags = append(res.Tags, trendingTag{
+			Tag:        row.Tag,
+			ChirpCount: row.ChirpCount,
+		})
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshaling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
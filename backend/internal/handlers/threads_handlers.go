@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+type threadReply struct {
+	chirpResponse
+	Replies []*threadReply `json:"replies"`
+}
+
+type threadResponse struct {
+	Ancestors []chirpResponse `json:"ancestors"`
+	Chirp     chirpResponse   `json:"chirp"`
+	Replies   []*threadReply  `json:"replies"`
+}
+
+func (cfg *ApiConfig) GetChirpThreadHandler(w http.ResponseWriter, req *http.Request) {
+	viewerID := optionalRequestUserID(req)
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error parsing",
+			Code:  400,
+		})
+		return
+	}
+
+	chirp, err := cfg.Queries.GetChirpById(req.Context(), chirpID)
+	if err != nil {
+		code := 500
+		msg := "Some error getting chirp by id"
+		if errors.Is(err, sql.ErrNoRows) {
+			code = 404
+			msg = "Chirp not found"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	ancestors, err := cfg.Queries.GetChirpAncestors(req.Context(), chirpID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get thread",
+			Code:  500,
+		})
+		return
+	}
+
+	replies, err := cfg.Queries.GetChirpReplies(req.Context(), chirpID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get thread",
+			Code:  500,
+		})
+		return
+	}
+
+	thread := append(append(ancestors, chirp), replies...)
+	responses, err := cfg.chirpResponses(req.Context(), thread, viewerID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error preparing chirps",
+			Code:  500,
+		})
+		return
+	}
+
+	res := threadResponse{
+		Ancestors: responses[:len(ancestors)],
+		Chirp:     responses[len(ancestors)],
+		Replies:   buildReplyTree(chirpID, responses[len(ancestors)+1:]),
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+// buildReplyTree nests replies under their parents. Replies have to be
+// ordered oldest first so every level keeps chronological order.
+func buildReplyTree(rootID uuid.UUID, replies []chirpResponse) []*threadReply {
+	children := map[uuid.UUID][]*threadReply{}
+	for _, reply := range replies {
+		node := &threadReply{
+			chirpResponse: reply,
+			Replies:       []*threadReply{},
+		}
+		children[*reply.ParentID] = append(children[*reply.ParentID], node)
+	}
+	for _, nodes := range children {
+		for _, node := range nodes {
+			if nested, ok := children[node.ID]; ok {
+				node.Replies = nested
+			}
+		}
+	}
+
+	if root, ok := children[rootID]; ok {
+		return root
+	}
+	return []*threadReply{}
+}
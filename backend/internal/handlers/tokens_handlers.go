@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/google/uuid"
+)
+
+const maxTokenNameLength = 100
+
+type personalAccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Token      string     `json:"token,omitempty"`
+}
+
+func newPersonalAccessTokenResponse(token database.PersonalAccessToken) personalAccessTokenResponse {
+	res := personalAccessTokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+	}
+	if token.LastUsedAt.Valid {
+		res.LastUsedAt = &token.LastUsedAt.Time
+	}
+	return res
+}
+
+// personalAccessTokenPrincipal looks up a personal access token by its hash.
+// Unknown or revoked tokens come back as sql.ErrNoRows.
+func (cfg *ApiConfig) personalAccessTokenPrincipal(ctx context.Context, tokenString string) (principal, error) {
+	token, err := cfg.Queries.GetPersonalAccessTokenByHash(ctx, auth.HashToken(tokenString))
+	if err != nil {
+		return principal{}, err
+	}
+	err = cfg.Queries.TouchPersonalAccessToken(ctx, database.TouchPersonalAccessTokenParams{
+		ID: token.ID,
+		LastUsedAt: sql.NullTime{
+			Time:  time.Now(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		log.Printf("Couldn't update last use of token %v: %v", token.ID, err)
+	}
+	return principal{
+		UserID: token.UserID,
+		Scopes: token.Scopes,
+	}, nil
+}
+
+func (cfg *ApiConfig) CreatePersonalAccessTokenHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+
+	name := strings.TrimSpace(params.Name)
+	if name == "" || len([]rune(name)) > maxTokenNameLength {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("invalid token name"),
+			Msg:   "Token name must be between 1 and 100 characters",
+			Code:  400,
+		})
+		return
+	}
+	scopes, err := auth.ParseScopes(params.Scopes)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	tokenString, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't create token",
+			Code:  500,
+		})
+		return
+	}
+	token, err := cfg.Queries.CreatePersonalAccessToken(req.Context(), database.CreatePersonalAccessTokenParams{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		TokenHash: auth.HashToken(tokenString),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't create token",
+			Code:  500,
+		})
+		return
+	}
+
+	// The raw token is only ever shown in this response.
+	res := newPersonalAccessTokenResponse(token)
+	res.Token = tokenString
+	data, err := json.Marshal(res)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) ListPersonalAccessTokensHandler(w http.ResponseWriter, req *http.Request) {
+	userID := requestUserID(req)
+
+	tokens, err := cfg.Queries.GetPersonalAccessTokensForUser(req.Context(), userID)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Weren't able to get tokens",
+			Code:  500,
+		})
+		return
+	}
+
+	res := make([]personalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, newPersonalAccessTokenResponse(token))
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) RevokePersonalAccessTokenHandler(w http.ResponseWriter, req *http.Request) {
+	tokenID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Token id is not valid",
+			Code:  400,
+		})
+		return
+	}
+
+	userID := requestUserID(req)
+
+	revoked, err := cfg.Queries.RevokePersonalAccessToken(req.Context(), database.RevokePersonalAccessTokenParams{
+		RevokedAt: sql.NullTime{
+			Time:  time.Now(),
+			Valid: true,
+		},
+		ID:     tokenID,
+		UserID: userID,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't revoke token",
+			Code:  500,
+		})
+		return
+	}
+	if revoked == 0 {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: errors.New("no active token with that id"),
+			Msg:   "Token not found",
+			Code:  404,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/handles"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/ShkolZ/chirpy/backend/internal/profiles"
+	"github.com/google/uuid"
+)
+
+func (cfg *ApiConfig) GetUserHandler(w http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "User id is not valid",
+			Code:  400,
+		})
+		return
+	}
+
+	user, err := cfg.Queries.GetUserById(req.Context(), userID)
+	respondWithProfile(w, req, user, err)
+}
+
+func (cfg *ApiConfig) GetUserByHandleHandler(w http.ResponseWriter, req *http.Request) {
+	user, err := cfg.Queries.GetUserByHandle(req.Context(), handles.Normalize(req.PathValue("handle")))
+	respondWithProfile(w, req, user, err)
+}
+
+func respondWithProfile(w http.ResponseWriter, req *http.Request, user database.User, err error) {
+	if err == nil && user.DeletedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		code := 500
+		msg := "Couldn't get user"
+		if errors.Is(err, sql.ErrNoRows) {
+			code = 404
+			msg = "User not found"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	data, err := json.Marshal(newUserProfileResponse(user))
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error marshalling",
+			Code:  500,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
+
+func (cfg *ApiConfig) UpdateProfileHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		DisplayName string `json:"display_name"`
+		Bio         string `json:"bio"`
+		AvatarURL   string `json:"avatar_url"`
+	}
+
+	userID := requestUserID(req)
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+	profile, err := profiles.Parse(profiles.Profile{
+		DisplayName: params.DisplayName,
+		Bio:         params.Bio,
+		AvatarURL:   params.AvatarURL,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   err.Error(),
+			Code:  400,
+		})
+		return
+	}
+
+	user, err := cfg.Queries.UpdateUserProfile(req.Context(), database.UpdateUserProfileParams{
+		ID:          userID,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		AvatarUrl:   profile.AvatarURL,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		code := 500
+		msg := "Couldn't update profile"
+		if errors.Is(err, sql.ErrNoRows) {
+			code = 404
+			msg = "User not found"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+
+	data, _ := json.Marshal(newUserProfileResponse(user))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(data)
+}
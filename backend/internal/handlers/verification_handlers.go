@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
+	"github.com/ShkolZ/chirpy/backend/internal/database"
+	"github.com/ShkolZ/chirpy/backend/internal/helpers"
+	"github.com/ShkolZ/chirpy/backend/internal/mailer"
+	"github.com/google/uuid"
+)
+
+const emailVerificationTTL = 48 * time.Hour
+
+func (cfg *ApiConfig) sendVerificationEmail(ctx context.Context, user database.User) error {
+	tokenID := uuid.New()
+	token, err := auth.MakeEmailVerificationToken(user.ID, tokenID, user.Email, cfg.SecretKey, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	err = cfg.Queries.CreateEmailVerification(ctx, database.CreateEmailVerificationParams{
+		ID:        tokenID,
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Chirpy email",
+		Body:    fmt.Sprintf("Use this token to verify your email address: %s\nIt expires in %v.", token, emailVerificationTTL),
+	})
+}
+
+// respondIfUnverified writes an error and returns true when userID may not
+// post yet because their email is unverified.
+func (cfg *ApiConfig) respondIfUnverified(w http.ResponseWriter, req *http.Request, userID uuid.UUID) bool {
+	user, err := cfg.Queries.GetUserById(req.Context(), userID)
+	if err == nil && user.DeletedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		code := 500
+		msg := "Couldn't get user"
+		if errors.Is(err, sql.ErrNoRows) {
+			code = 404
+			msg = "User not found"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return true
+	}
+	if !user.EmailVerifiedAt.Valid {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("user %v has not verified their email", userID),
+			Msg:   "Verify your email before posting",
+			Code:  403,
+		})
+		return true
+	}
+	return false
+}
+
+func (cfg *ApiConfig) VerifyEmailHandler(w http.ResponseWriter, req *http.Request) {
+	type reqParams struct {
+		Token string `json:"token"`
+	}
+
+	params := reqParams{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&params); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Some error decoding",
+			Code:  400,
+		})
+		return
+	}
+
+	userID, tokenID, email, err := auth.ValidateEmailVerificationToken(params.Token, cfg.SecretKey)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Token is invalid or expired",
+			Code:  400,
+		})
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(req.Context(), nil)
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't verify email",
+			Code:  500,
+		})
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	now := time.Now()
+	used, err := qtx.UseEmailVerification(req.Context(), database.UseEmailVerificationParams{
+		UsedAt: now,
+		ID:     tokenID,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't verify email",
+			Code:  500,
+		})
+		return
+	}
+	if used == 0 {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("verification %v is unknown, used or expired", tokenID),
+			Msg:   "Token is invalid or expired",
+			Code:  400,
+		})
+		return
+	}
+	verified, err := qtx.SetEmailVerified(req.Context(), database.SetEmailVerifiedParams{
+		VerifiedAt: now,
+		ID:         userID,
+		Email:      email,
+	})
+	if err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't verify email",
+			Code:  500,
+		})
+		return
+	}
+	if verified == 0 {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("user %v no longer has email %v", userID, email),
+			Msg:   "Token is invalid or expired",
+			Code:  400,
+		})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't verify email",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (cfg *ApiConfig) ResendVerificationHandler(w http.ResponseWriter, req *http.Request) {
+	userID := requestUserID(req)
+
+	user, err := cfg.Queries.GetUserById(req.Context(), userID)
+	if err == nil && user.DeletedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		code := 500
+		msg := "Couldn't get user"
+		if errors.Is(err, sql.ErrNoRows) {
+			code = 404
+			msg = "User not found"
+		}
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   msg,
+			Code:  code,
+		})
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: fmt.Errorf("user %v is already verified", userID),
+			Msg:   "Email is already verified",
+			Code:  409,
+		})
+		return
+	}
+
+	if err := cfg.sendVerificationEmail(req.Context(), user); err != nil {
+		helpers.RespondWithError(w, req, &helpers.ErrorResponse{
+			Error: err,
+			Msg:   "Couldn't send verification email",
+			Code:  500,
+		})
+		return
+	}
+
+	w.WriteHeader(202)
+}
@@ -0,0 +1,44 @@
+package handles
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MinLength = 3
+	MaxLength = 15
+)
+
+var (
+	ErrInvalid = errors.New("handle must be 3-15 letters, digits or underscores")
+
+	handlePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Normalize strips a leading "@" and lowercases the handle. Handles are
+// stored normalized so lookups don't depend on how a user typed them.
+func Normalize(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+func Valid(handle string) bool {
+	return len(handle) >= MinLength && len(handle) <= MaxLength && handlePattern.MatchString(handle)
+}
+
+// Parse normalizes handle and checks that it is valid.
+func Parse(handle string) (string, error) {
+	handle = Normalize(handle)
+	if !Valid(handle) {
+		return "", ErrInvalid
+	}
+	return handle, nil
+}
+
+// ForUser is the handle given to users that didn't pick one.
+func ForUser(id uuid.UUID) string {
+	return "user_" + strings.ReplaceAll(id.String(), "-", "")[:10]
+}
@@ -2,46 +2,44 @@ package helpers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strings"
+
+	"github.com/lib/pq"
 )
 
 type ErrorResponse struct {
-	Error error
-	Msg   string
-	Code  int
-}
-
-func CleanInput(i string) string {
-	words := strings.Split(i, " ")
-	curseWords := []string{"kerfuffle", "sharbert", "fornax"}
-
-	for i := range words {
-		word := strings.ToLower(words[i])
-		for _, cWord := range curseWords {
-			if word == cWord {
-				words[i] = "****"
-				log.Println("here")
-				break
-			}
-		}
-	}
-
-	cleanString := strings.Join(words, " ")
-	return cleanString
+	Error   error
+	Msg     string
+	Code    int
+	Details any
 }
 
 func RespondWithError(w http.ResponseWriter, req *http.Request, err *ErrorResponse) {
 	type res struct {
-		Msg string `json:"msg"`
+		Msg    string `json:"msg"`
+		Errors any    `json:"errors,omitempty"`
 	}
 
 	log.Printf("%v: %v", err.Msg, err.Error)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(err.Code)
 	data, _ := json.Marshal(res{
-		Msg: err.Msg,
+		Msg:    err.Msg,
+		Errors: err.Details,
 	})
 	w.Write(data)
 }
+
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// IsUniqueViolationOn reports whether err is a unique violation of the named
+// constraint or index.
+func IsUniqueViolationOn(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
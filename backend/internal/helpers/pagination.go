@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+func ParsePageLimit(query url.Values) (int32, error) {
+	raw := query.Get("limit")
+	if raw == "" {
+		return DefaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > MaxPageLimit {
+		return 0, errors.New("limit must be a number between 1 and " + strconv.Itoa(MaxPageLimit))
+	}
+	return int32(limit), nil
+}
+
+func EncodeCursor(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeCursor(cursor string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return errors.New("cursor is not valid")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("cursor is not valid")
+	}
+	return nil
+}
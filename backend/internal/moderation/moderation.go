@@ -0,0 +1,229 @@
+package moderation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionReject Action = "reject"
+	ActionFlag   Action = "flag"
+)
+
+const mask = "****"
+
+var DefaultWords = []string{"kerfuffle", "sharbert", "fornax"}
+
+func ParseAction(s string) (Action, error) {
+	switch action := Action(strings.ToLower(strings.TrimSpace(s))); action {
+	case ActionMask, ActionReject, ActionFlag:
+		return action, nil
+	case "":
+		return ActionMask, nil
+	default:
+		return "", fmt.Errorf("unknown moderation action %q", s)
+	}
+}
+
+type Result struct {
+	// Text is the checked text, with matches masked when Action is ActionMask.
+	Text    string
+	Matches []string
+	Action  Action
+}
+
+func (r Result) Matched() bool {
+	return len(r.Matches) > 0
+}
+
+type Filter interface {
+	Check(text string) Result
+	Action() Action
+}
+
+// WordList is implemented by filters whose word list admins can change at
+// runtime.
+type WordList interface {
+	Words() []string
+	SetWords(words []string)
+}
+
+// WordFilter matches whole words against a list that can be swapped while
+// the server is running.
+type WordFilter struct {
+	mu     sync.RWMutex
+	words  map[string]struct{}
+	action Action
+}
+
+func NewWordFilter(words []string, action Action) *WordFilter {
+	f := &WordFilter{action: action}
+	f.SetWords(words)
+	return f
+}
+
+func (f *WordFilter) SetWords(words []string) {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		if normalized := Normalize(word); normalized != "" {
+			set[normalized] = struct{}{}
+		}
+	}
+	f.mu.Lock()
+	f.words = set
+	f.mu.Unlock()
+}
+
+func (f *WordFilter) Words() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	words := make([]string, 0, len(f.words))
+	for word := range f.words {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+func (f *WordFilter) Action() Action {
+	return f.action
+}
+
+func (f *WordFilter) Check(text string) Result {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	res := Result{Action: f.action}
+	var b strings.Builder
+	for _, tok := range tokenize(text) {
+		if !tok.word {
+			b.WriteString(tok.text)
+			continue
+		}
+
+		// Try the whole run first so "$harbert" matches, then without the
+		// symbols around it so "@fornax" or "fornax$" still do.
+		lead, core, trail := "", tok.text, ""
+		normalized := Normalize(tok.text)
+		if _, ok := f.words[normalized]; !ok {
+			lead, core, trail = trimSymbols(tok.text)
+			normalized = Normalize(core)
+		}
+		if _, ok := f.words[normalized]; !ok || core == "" {
+			b.WriteString(tok.text)
+			continue
+		}
+
+		res.Matches = append(res.Matches, normalized)
+		b.WriteString(lead)
+		if f.action == ActionMask {
+			b.WriteString(mask)
+		} else {
+			b.WriteString(core)
+		}
+		b.WriteString(trail)
+	}
+	res.Text = b.String()
+	return res
+}
+
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'@': 'a',
+	'$': 's',
+}
+
+// Normalize lower-cases a word and undoes leetspeak so "K3rfuffl3" and
+// "kerfuffle" compare equal. Digits without a leet meaning are kept, so
+// "kerfuffle2" stays a different word; anything else is dropped.
+func Normalize(word string) string {
+	var b strings.Builder
+	for _, r := range word {
+		if replacement, ok := leet[r]; ok {
+			r = replacement
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+type token struct {
+	text string
+	word bool
+}
+
+func isWordRune(r rune) bool {
+	_, isLeet := leet[r]
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || isLeet
+}
+
+// tokenize splits text into words and the separators between them, so the
+// text can be put back together unchanged apart from masked words.
+func tokenize(text string) []token {
+	tokens := []token{}
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		word := isWordRune(runes[i])
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) == word {
+			j++
+		}
+		tokens = append(tokens, token{text: string(runes[i:j]), word: word})
+		i = j
+	}
+	return tokens
+}
+
+func trimSymbols(word string) (lead, core, trail string) {
+	isAlnum := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	start := strings.IndexFunc(word, isAlnum)
+	if start == -1 {
+		return word, "", ""
+	}
+	end := strings.LastIndexFunc(word, isAlnum)
+	_, size := utf8.DecodeRuneInString(word[end:])
+	return word[:start], word[start : end+size], word[end+size:]
+}
+
+// LoadWordsFile reads one word per line. Blank lines and lines starting with
+// # are skipped.
+func LoadWordsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
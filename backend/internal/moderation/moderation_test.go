@@ -0,0 +1,123 @@
+package moderation
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		want string
+	}{
+		{"plain", "kerfuffle", "kerfuffle"},
+		{"upper case", "KerFUFFLE", "kerfuffle"},
+		{"leet digits", "k3rfuffl3", "kerfuffle"},
+		{"leet symbols", "$h@rbert", "sharbert"},
+		{"all leet digits", "0134578", "oieastb"},
+		{"unmapped trailing digit", "kerfuffle2", "kerfuffle2"},
+		{"unmapped leading digit", "2kerfuffle", "2kerfuffle"},
+		{"unmapped digits only", "2969", "2969"},
+		{"punctuation dropped", "for-nax", "fornax"},
+		{"unicode letters", "ÇAFÉ", "çafé"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.word); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []token
+	}{
+		{"empty", "", []token{}},
+		{"single word", "hello", []token{{"hello", true}}},
+		{"words and spaces", "hi  there", []token{{"hi", true}, {"  ", false}, {"there", true}}},
+		{"punctuation separates", "Kerfuffle!", []token{{"Kerfuffle", true}, {"!", false}}},
+		{"comma separates", "fornax, ok", []token{{"fornax", true}, {", ", false}, {"ok", true}}},
+		{"leet symbols stay in word", "$harbert", []token{{"$harbert", true}}},
+		{"at sign stays in word", "@fornax.", []token{{"@fornax", true}, {".", false}}},
+		{"unicode", "héllo wörld", []token{{"héllo", true}, {" ", false}, {"wörld", true}}},
+		{"only separators", " ,.!", []token{{" ,.!", false}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %#v, want %#v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimSymbols(t *testing.T) {
+	tests := []struct {
+		name              string
+		word              string
+		lead, core, trail string
+	}{
+		{"nothing to trim", "fornax", "", "fornax", ""},
+		{"leading symbol", "@fornax", "@", "fornax", ""},
+		{"trailing symbol", "fornax$", "", "fornax", "$"},
+		{"both sides", "@@fornax$", "@@", "fornax", "$"},
+		{"symbols inside kept", "f@rnax", "", "f@rnax", ""},
+		{"digits are core", "2fornax", "", "2fornax", ""},
+		{"only symbols", "@$", "@$", "", ""},
+		{"multibyte trail", "éclair@", "", "éclair", "@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lead, core, trail := trimSymbols(tt.word)
+			if lead != tt.lead || core != tt.core || trail != tt.trail {
+				t.Errorf("trimSymbols(%q) = %q, %q, %q, want %q, %q, %q",
+					tt.word, lead, core, trail, tt.lead, tt.core, tt.trail)
+			}
+		})
+	}
+}
+
+func TestWordFilterCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  Action
+		text    string
+		want    string
+		matches []string
+	}{
+		{"clean text", ActionMask, "hello there", "hello there", nil},
+		{"masks word", ActionMask, "what a kerfuffle", "what a ****", []string{"kerfuffle"}},
+		{"case insensitive", ActionMask, "Kerfuffle!", "****!", []string{"kerfuffle"}},
+		{"trailing comma", ActionMask, "fornax, yes", "****, yes", []string{"fornax"}},
+		{"leetspeak", ActionMask, "k3rfuffl3", "****", []string{"kerfuffle"}},
+		{"leet symbol as letter", ActionMask, "$harbert", "****", []string{"sharbert"}},
+		{"symbol kept around match", ActionMask, "@fornax", "@****", []string{"fornax"}},
+		{"trailing digit is another word", ActionMask, "kerfuffle2", "kerfuffle2", nil},
+		{"leading digit is another word", ActionMask, "2kerfuffle", "2kerfuffle", nil},
+		{"substring not matched", ActionMask, "kerfuffles", "kerfuffles", nil},
+		{"flag keeps text", ActionFlag, "a kerfuffle", "a kerfuffle", []string{"kerfuffle"}},
+		{"several matches", ActionMask, "fornax and sharbert", "**** and ****", []string{"fornax", "sharbert"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewWordFilter(DefaultWords, tt.action).Check(tt.text)
+			if res.Text != tt.want {
+				t.Errorf("Check(%q).Text = %q, want %q", tt.text, res.Text, tt.want)
+			}
+			if !slices.Equal(res.Matches, tt.matches) {
+				t.Errorf("Check(%q).Matches = %v, want %v", tt.text, res.Matches, tt.matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 160
+	MaxAvatarURLLength   = 2048
+)
+
+type Profile struct {
+	DisplayName string
+	Bio         string
+	AvatarURL   string
+}
+
+// Parse trims the profile fields and checks their limits. An empty avatar
+// URL clears the avatar.
+func Parse(p Profile) (Profile, error) {
+	p = Profile{
+		DisplayName: strings.TrimSpace(p.DisplayName),
+		Bio:         strings.TrimSpace(p.Bio),
+		AvatarURL:   strings.TrimSpace(p.AvatarURL),
+	}
+	if utf8.RuneCountInString(p.DisplayName) > MaxDisplayNameLength {
+		return Profile{}, fmt.Errorf("display_name must be at most %d characters", MaxDisplayNameLength)
+	}
+	if utf8.RuneCountInString(p.Bio) > MaxBioLength {
+		return Profile{}, fmt.Errorf("bio must be at most %d characters", MaxBioLength)
+	}
+	if p.AvatarURL != "" {
+		if len(p.AvatarURL) > MaxAvatarURLLength {
+			return Profile{}, fmt.Errorf("avatar_url must be at most %d characters", MaxAvatarURLLength)
+		}
+		u, err := url.Parse(p.AvatarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Profile{}, errors.New("avatar_url must be an http or https URL")
+		}
+	}
+	return p, nil
+}
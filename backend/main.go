@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
 	"sync/atomic"
+	"time"
 
+	"github.com/ShkolZ/chirpy/backend/internal/auth"
 	"github.com/ShkolZ/chirpy/backend/internal/database"
 	"github.com/ShkolZ/chirpy/backend/internal/handlers"
+	"github.com/ShkolZ/chirpy/backend/internal/mailer"
+	"github.com/ShkolZ/chirpy/backend/internal/moderation"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
@@ -18,42 +23,167 @@ func main() {
 	dbURL := os.Getenv("DB_URL")
 	secretKey := os.Getenv("SECRET_KEY")
 	polkaKey := os.Getenv("POLKA_KEY")
+	adminKey := os.Getenv("ADMIN_KEY")
+	chirpEditWindow := durationEnv("CHIRP_EDIT_WINDOW", 15*time.Minute)
+	accessTokenTTL := durationEnv("ACCESS_TOKEN_TTL", time.Hour)
+	refreshTokenTTL := durationEnv("REFRESH_TOKEN_TTL", 60*24*time.Hour)
 	db, err := sql.Open("postgres", dbURL)
 	dbQueries := database.New(db)
 
 	if err != nil {
 		log.Fatalln(err)
 	}
+	moderationAction, err := moderation.ParseAction(os.Getenv("MODERATION_ACTION"))
+	if err != nil {
+		log.Fatalf("MODERATION_ACTION: %v", err)
+	}
+	moderationWords, configured, err := handlers.StoredModerationWords(context.Background(), dbQueries)
+	if err != nil {
+		log.Printf("Couldn't load moderation words from db: %v", err)
+	}
+	if !configured {
+		moderationWords = moderation.DefaultWords
+		if path := os.Getenv("MODERATION_WORDS_FILE"); path != "" {
+			moderationWords, err = moderation.LoadWordsFile(path)
+			if err != nil {
+				log.Fatalf("MODERATION_WORDS_FILE: %v", err)
+			}
+		}
+	}
+
+	signingAlg := os.Getenv("JWT_SIGNING_ALG")
+	if signingAlg == "" {
+		signingAlg = auth.AlgEdDSA
+	}
+	keysDir := os.Getenv("JWT_KEYS_DIR")
+	if keysDir == "" {
+		keysDir = "keys"
+	}
+	// A replaced key has to keep verifying until the last access token it
+	// signed has expired.
+	keys, err := auth.LoadKeySet(keysDir, signingAlg, accessTokenTTL)
+	if err != nil {
+		log.Fatalf("JWT_KEYS_DIR: %v", err)
+	}
+	// JWT_KEY_ROTATION=0 turns rotation off; the directory is still
+	// reloaded so keys added by other instances or by hand are picked up.
+	go keys.RotateEvery(context.Background(), durationEnv("JWT_KEY_ROTATION", 30*24*time.Hour))
+
+	denyList := auth.NewDenyList()
+	if err := dbQueries.DeleteExpiredDeniedAccessTokens(context.Background(), time.Now()); err != nil {
+		log.Printf("Couldn't prune denied access tokens: %v", err)
+	}
+	deniedTokens, err := dbQueries.GetDeniedAccessTokens(context.Background(), time.Now())
+	if err != nil {
+		log.Printf("Couldn't load denied access tokens from db: %v", err)
+	}
+	for _, denied := range deniedTokens {
+		denyList.Deny(denied.Jti, denied.ExpiresAt)
+	}
+
+	var mail mailer.Mailer
+	switch os.Getenv("MAILER") {
+	case "", "log":
+		mail = mailer.NewLogMailer(log.Default())
+	case "file":
+		path := os.Getenv("MAILER_FILE")
+		if path == "" {
+			path = "mail.log"
+		}
+		mail = mailer.NewFileMailer(path)
+	case "smtp":
+		mail = mailer.NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	default:
+		log.Fatalf("MAILER: unknown mailer %q", os.Getenv("MAILER"))
+	}
+
 	mux := http.NewServeMux()
 	fileServeHandler := http.FileServer(http.Dir("."))
 	apiCfg := handlers.ApiConfig{
-		FileserverHits: atomic.Int32{},
-		Queries:        dbQueries,
-		SecretKey:      secretKey,
-		PolkaKey:       polkaKey,
+		FileserverHits:  atomic.Int32{},
+		DB:              db,
+		Queries:         dbQueries,
+		SecretKey:       secretKey,
+		PolkaKey:        polkaKey,
+		AdminKey:        adminKey,
+		ChirpEditWindow: chirpEditWindow,
+		AccessTokenTTL:  accessTokenTTL,
+		RefreshTokenTTL: refreshTokenTTL,
+		DenyList:        denyList,
+		Keys:            keys,
+		Moderation:      moderation.NewWordFilter(moderationWords, moderationAction),
+		Mailer:          mail,
 	}
 
 	//GET Requests
 	mux.Handle("/app/", apiCfg.MetricsIncMiddleware(http.StripPrefix("/app/", fileServeHandler)))
+	mux.HandleFunc("GET /.well-known/jwks.json", apiCfg.LoggingMiddleware(apiCfg.JWKSHandler))
 	mux.HandleFunc("GET /api/healthz", apiCfg.LoggingMiddleware(apiCfg.HealthzHandler))
 	mux.HandleFunc("GET /admin/metrics", apiCfg.LoggingMiddleware(apiCfg.MetricsHandler))
-	mux.HandleFunc("GET /api/chirps", apiCfg.LoggingMiddleware(apiCfg.GetChirpsHandler))
-	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.LoggingMiddleware(apiCfg.GetChirpHandler))
+	mux.HandleFunc("GET /admin/moderation/words", apiCfg.LoggingMiddleware(apiCfg.AdminMiddleware(apiCfg.GetModerationWordsHandler)))
+	mux.HandleFunc("GET /admin/moderation/flags", apiCfg.LoggingMiddleware(apiCfg.AdminMiddleware(apiCfg.GetModerationFlagsHandler)))
+	mux.HandleFunc("GET /api/chirps", apiCfg.LoggingMiddleware(apiCfg.OptionalAuth(apiCfg.GetChirpsHandler, auth.ScopeChirpsRead)))
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.LoggingMiddleware(apiCfg.OptionalAuth(apiCfg.SearchChirpsHandler, auth.ScopeChirpsRead)))
+	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.LoggingMiddleware(apiCfg.OptionalAuth(apiCfg.GetChirpHandler, auth.ScopeChirpsRead)))
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.LoggingMiddleware(apiCfg.GetChirpRevisionsHandler))
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.LoggingMiddleware(apiCfg.OptionalAuth(apiCfg.GetChirpThreadHandler, auth.ScopeChirpsRead)))
+	mux.HandleFunc("GET /api/sessions", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.ListSessionsHandler)))
+	mux.HandleFunc("GET /api/tags/trending", apiCfg.LoggingMiddleware(apiCfg.GetTrendingTagsHandler))
+	mux.HandleFunc("GET /api/tags/{tag}/chirps", apiCfg.LoggingMiddleware(apiCfg.OptionalAuth(apiCfg.GetTagChirpsHandler, auth.ScopeChirpsRead)))
+	mux.HandleFunc("GET /api/users/me/export", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.ExportAccountHandler)))
+	mux.HandleFunc("GET /api/users/me/mentions", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.GetMyMentionsHandler, auth.ScopeChirpsRead)))
+	mux.HandleFunc("GET /api/tokens", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.ListPersonalAccessTokensHandler)))
+	mux.HandleFunc("GET /api/timeline", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.GetTimelineHandler, auth.ScopeChirpsRead)))
+	mux.HandleFunc("GET /api/users/{id}", apiCfg.LoggingMiddleware(apiCfg.GetUserHandler))
+	mux.HandleFunc("GET /api/users/by-handle/{handle}", apiCfg.LoggingMiddleware(apiCfg.GetUserByHandleHandler))
+	mux.HandleFunc("GET /api/users/{id}/{relation}", apiCfg.LoggingMiddleware(apiCfg.GetUserRelationHandler))
 
 	//POST Requests
 	mux.HandleFunc("POST /admin/reset", apiCfg.LoggingMiddleware(apiCfg.ResetHandler))
+	mux.HandleFunc("POST /admin/moderation/flags/{flagID}/resolve", apiCfg.LoggingMiddleware(apiCfg.AdminMiddleware(apiCfg.ResolveModerationFlagHandler)))
 	mux.HandleFunc("POST /api/validate_chirp", apiCfg.LoggingMiddleware(apiCfg.ValidateChirpHandler))
 	mux.HandleFunc("POST /api/users", apiCfg.LoggingMiddleware(apiCfg.CreateUserHandler))
-	mux.HandleFunc("POST /api/chirps", apiCfg.LoggingMiddleware(apiCfg.CreateChirpHandler))
+	mux.HandleFunc("POST /api/chirps", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.CreateChirpHandler, auth.ScopeChirpsWrite)))
 	mux.HandleFunc("POST /api/login", apiCfg.LoggingMiddleware(apiCfg.LoginHandler))
 	mux.HandleFunc("POST /api/refresh", apiCfg.LoggingMiddleware(apiCfg.RefreshTokenHandler))
+	mux.HandleFunc("POST /admin/access-tokens/deny", apiCfg.LoggingMiddleware(apiCfg.AdminMiddleware(apiCfg.DenyAccessTokenHandler)))
+	mux.HandleFunc("POST /api/access-token/revoke", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.RevokeAccessTokenHandler)))
 	mux.HandleFunc("POST /api/revoke", apiCfg.LoggingMiddleware(apiCfg.RevokeRefreshTokenHandler))
+	mux.HandleFunc("POST /api/password/forgot", apiCfg.LoggingMiddleware(apiCfg.ForgotPasswordHandler))
+	mux.HandleFunc("POST /api/password/reset", apiCfg.LoggingMiddleware(apiCfg.ResetPasswordHandler))
+	mux.HandleFunc("POST /api/tokens", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.CreatePersonalAccessTokenHandler)))
+	mux.HandleFunc("POST /api/sessions/revoke-all", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.RevokeAllSessionsHandler)))
+	mux.HandleFunc("POST /api/users/verify", apiCfg.LoggingMiddleware(apiCfg.VerifyEmailHandler))
+	mux.HandleFunc("POST /api/users/verify/resend", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.ResendVerificationHandler)))
+	mux.HandleFunc("POST /api/users/me/email", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.RequestEmailChangeHandler)))
+	mux.HandleFunc("POST /api/users/me/email/confirm", apiCfg.LoggingMiddleware(apiCfg.ConfirmEmailChangeHandler))
+	mux.HandleFunc("POST /api/chirps/{chirpID}/like", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.LikeChirpHandler, auth.ScopeChirpsWrite)))
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.RechirpHandler, auth.ScopeChirpsWrite)))
+	mux.HandleFunc("POST /api/users/{id}/follow", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.FollowUserHandler)))
 
 	//PUT REQUESTS
 	mux.HandleFunc("PUT /api/polka/webhooks", apiCfg.LoggingMiddleware(apiCfg.UserChirpyRedHandler))
+	mux.HandleFunc("PUT /api/users", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.UpdateCredentialsHandler)))
+	mux.HandleFunc("PUT /api/users/me/password", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.ChangePasswordHandler)))
+	mux.HandleFunc("PUT /api/users/me/profile", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.UpdateProfileHandler, auth.ScopeProfileWrite)))
+	mux.HandleFunc("PUT /api/users/me/handle", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.UpdateHandleHandler, auth.ScopeProfileWrite)))
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.UpdateChirpHandler, auth.ScopeChirpsWrite)))
+	mux.HandleFunc("PUT /admin/moderation/words", apiCfg.LoggingMiddleware(apiCfg.AdminMiddleware(apiCfg.UpdateModerationWordsHandler)))
 
 	//DELETE REQUESTS
-	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.LoggingMiddleware(apiCfg.DeleteChirpHandler))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.DeleteChirpHandler, auth.ScopeChirpsWrite)))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.UnlikeChirpHandler, auth.ScopeChirpsWrite)))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.UndoRechirpHandler, auth.ScopeChirpsWrite)))
+	mux.HandleFunc("DELETE /api/sessions/{id}", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.RevokeSessionHandler)))
+	mux.HandleFunc("DELETE /api/tokens/{id}", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.RevokePersonalAccessTokenHandler)))
+	mux.HandleFunc("DELETE /api/users/me", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.DeleteAccountHandler)))
+	mux.HandleFunc("DELETE /api/users/{id}/follow", apiCfg.LoggingMiddleware(apiCfg.RequireAuth(apiCfg.UnfollowUserHandler)))
 
 	log.Println("Server is starting...")
 
@@ -66,3 +196,15 @@ func main() {
 	}
 
 }
+
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+	return d
+}
@@ -0,0 +1,23 @@
+-- name: LikeChirp :exec
+INSERT INTO chirp_likes(chirp_id, user_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+) ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: UnlikeChirp :exec
+DELETE FROM chirp_likes
+WHERE chirp_id = $1 and user_id = $2;
+
+-- name: DeleteLikesByUser :exec
+DELETE FROM chirp_likes
+WHERE user_id = $1;
+
+-- name: GetChirpLikeStats :many
+SELECT chirp_id,
+       COUNT(*) AS like_count,
+       COALESCE(BOOL_OR(user_id = sqlc.narg(viewer_id)::uuid), false)::boolean AS liked_by_me
+FROM chirp_likes
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+GROUP BY chirp_id;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- name: AddChirpMention :exec
+INSERT INTO chirp_mentions(chirp_id, user_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+) ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
+
+-- name: GetChirpMentions :many
+SELECT chirp_mentions.chirp_id, users.id AS user_id, users.handle FROM chirp_mentions
+JOIN users ON users.id = chirp_mentions.user_id
+WHERE chirp_mentions.chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+ORDER BY users.handle;
+
+-- name: GetMentionsOfUser :many
+SELECT chirps.* FROM chirps
+JOIN chirp_mentions ON chirp_mentions.chirp_id = chirps.id
+WHERE chirp_mentions.user_id = sqlc.arg(user_id)
+  AND chirps.deleted_at IS NULL
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+       OR (chirps.created_at, chirps.id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
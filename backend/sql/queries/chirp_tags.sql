@@ -0,0 +1,30 @@
+-- name: AddChirpTag :exec
+INSERT INTO chirp_tags(chirp_id, tag, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+) ON CONFLICT (chirp_id, tag) DO NOTHING;
+
+-- name: DeleteChirpTags :exec
+DELETE FROM chirp_tags
+WHERE chirp_id = $1;
+
+-- name: GetChirpsByTag :many
+SELECT chirps.* FROM chirps
+JOIN chirp_tags ON chirp_tags.chirp_id = chirps.id
+WHERE chirp_tags.tag = sqlc.arg(tag)
+  AND chirps.deleted_at IS NULL
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+       OR (chirps.created_at, chirps.id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetTrendingTags :many
+SELECT chirp_tags.tag, COUNT(*) AS chirp_count FROM chirp_tags
+JOIN chirps ON chirps.id = chirp_tags.chirp_id
+WHERE chirp_tags.created_at >= sqlc.arg(since)
+  AND chirps.deleted_at IS NULL
+GROUP BY chirp_tags.tag
+ORDER BY chirp_count DESC, chirp_tags.tag
+LIMIT sqlc.arg(page_limit);
@@ -1,22 +1,150 @@
 -- name: CreateChirp :one
-INSERT INTO chirps(id, body, created_at, updated_at, user_id )
+INSERT INTO chirps(id, body, created_at, updated_at, user_id, parent_id, quote_of)
 VALUES (
     $1,
     $2,
     $3,
     $4,
+    $5,
+    $6,
+    $7
+) RETURNING *;
+
+-- name: CreateRechirp :one
+INSERT INTO chirps(id, body, created_at, updated_at, user_id, repost_of)
+VALUES (
+    $1,
+    '',
+    $2,
+    $3,
+    $4,
     $5
 ) RETURNING *;
 
+-- name: GetRechirp :one
+SELECT * FROM chirps
+WHERE user_id = $1 and repost_of = $2;
+
+-- name: DeleteRechirp :exec
+DELETE FROM chirps
+WHERE user_id = $1 and repost_of = $2;
+
 -- name: GetChirps :many
 SELECT * FROM chirps
-ORDER BY created_at ASC;
+WHERE deleted_at IS NULL
+  AND (sqlc.narg(author_id)::uuid IS NULL OR user_id = sqlc.narg(author_id)::uuid)
+  AND (sqlc.narg(since)::timestamp IS NULL OR created_at >= sqlc.narg(since)::timestamp)
+  AND (sqlc.narg(until)::timestamp IS NULL OR created_at <= sqlc.narg(until)::timestamp)
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+       OR (created_at, id) > (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetChirpsDesc :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+  AND (sqlc.narg(author_id)::uuid IS NULL OR user_id = sqlc.narg(author_id)::uuid)
+  AND (sqlc.narg(since)::timestamp IS NULL OR created_at >= sqlc.narg(since)::timestamp)
+  AND (sqlc.narg(until)::timestamp IS NULL OR created_at <= sqlc.narg(until)::timestamp)
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+       OR (created_at, id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetTimeline :many
+SELECT chirps.* FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE follows.follower_id = sqlc.arg(follower_id)
+  AND chirps.deleted_at IS NULL
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+       OR (chirps.created_at, chirps.id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: SearchChirps :many
+SELECT chirps.*, ts_rank(body_tsv, websearch_to_tsquery('english', sqlc.arg(query)))::real AS rank
+FROM chirps
+WHERE deleted_at IS NULL
+  AND body_tsv @@ websearch_to_tsquery('english', sqlc.arg(query))
+ORDER BY rank DESC, created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: GetChirpsByUser :many
+SELECT * FROM chirps
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at, id;
 
 -- name: GetChirpById :one
 SELECT * FROM chirps
 WHERE id = $1;
 
--- name: DeleteChirpById :exec 
+-- name: GetChirpByIdForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetChirpsByIds :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT c.parent_id, 1 AS depth FROM chirps c
+    WHERE c.id = sqlc.arg(chirp_id)::uuid
+    UNION ALL
+    SELECT c.parent_id, a.depth + 1 FROM chirps c
+    JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT chirps.* FROM chirps
+JOIN ancestors ON chirps.id = ancestors.parent_id
+ORDER BY ancestors.depth DESC;
+
+-- name: GetChirpReplies :many
+WITH RECURSIVE replies AS (
+    SELECT c.id FROM chirps c
+    WHERE c.parent_id = sqlc.arg(chirp_id)::uuid
+    UNION ALL
+    SELECT c.id FROM chirps c
+    JOIN replies r ON c.parent_id = r.id
+)
+SELECT chirps.* FROM chirps
+JOIN replies ON chirps.id = replies.id
+ORDER BY chirps.created_at ASC, chirps.id ASC;
+
+-- name: ChirpHasReplies :one
+SELECT EXISTS (
+    SELECT 1 FROM chirps
+    WHERE parent_id = sqlc.arg(chirp_id)::uuid
+);
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2,
+    updated_at = $3
+WHERE id = $1
+  AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteChirpById :execrows
 DELETE FROM chirps
 WHERE id = $1 and user_id = $2;
 
+-- name: SoftDeleteChirpById :execrows
+UPDATE chirps
+SET body = '',
+    deleted_at = $3,
+    updated_at = $3
+WHERE id = $1 and user_id = $2;
+
+-- name: HandOverRepliedChirps :exec
+UPDATE chirps
+SET user_id = sqlc.arg(new_user_id),
+    body = '',
+    updated_at = sqlc.arg(deleted_at),
+    deleted_at = sqlc.arg(deleted_at)
+WHERE user_id = sqlc.arg(user_id)
+  AND repost_of IS NULL
+  AND EXISTS (
+      SELECT 1 FROM chirps AS replies
+      WHERE replies.parent_id = chirps.id
+  );
@@ -0,0 +1,15 @@
+-- name: DenyAccessToken :exec
+INSERT INTO denied_access_tokens(jti, expires_at, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+) ON CONFLICT (jti) DO NOTHING;
+
+-- name: GetDeniedAccessTokens :many
+SELECT * FROM denied_access_tokens
+WHERE expires_at > $1;
+
+-- name: DeleteExpiredDeniedAccessTokens :exec
+DELETE FROM denied_access_tokens
+WHERE expires_at <= $1;
@@ -0,0 +1,22 @@
+-- name: CreateEmailChange :exec
+INSERT INTO email_changes(token_hash, user_id, new_email, expires_at, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: DeleteEmailChangesForUser :exec
+DELETE FROM email_changes
+WHERE user_id = $1;
+
+-- name: GetEmailChange :one
+SELECT * FROM email_changes
+WHERE token_hash = $1;
+
+-- name: UseEmailChange :execrows
+UPDATE email_changes
+SET used_at = $2
+WHERE token_hash = $1 AND used_at IS NULL;
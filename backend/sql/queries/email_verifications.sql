@@ -0,0 +1,16 @@
+-- name: CreateEmailVerification :exec
+INSERT INTO email_verifications(id, user_id, email, expires_at, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: UseEmailVerification :execrows
+UPDATE email_verifications
+SET used_at = sqlc.arg(used_at)::timestamp
+WHERE id = sqlc.arg(id)
+  AND used_at IS NULL
+  AND expires_at > sqlc.arg(used_at)::timestamp;
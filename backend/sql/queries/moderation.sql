@@ -0,0 +1,40 @@
+-- name: GetModerationWords :many
+SELECT word FROM moderation_words
+ORDER BY word ASC;
+
+-- name: DeleteModerationWords :exec
+DELETE FROM moderation_words;
+
+-- name: AddModerationWord :exec
+INSERT INTO moderation_words(word, created_at)
+VALUES (
+    $1,
+    $2
+) ON CONFLICT (word) DO NOTHING;
+
+-- name: GetModerationWordListUpdatedAt :one
+SELECT updated_at FROM moderation_word_list;
+
+-- name: SetModerationWordListUpdatedAt :exec
+INSERT INTO moderation_word_list(updated_at)
+VALUES ($1)
+ON CONFLICT (id) DO UPDATE SET updated_at = EXCLUDED.updated_at;
+
+-- name: CreateModerationFlag :exec
+INSERT INTO moderation_flags(id, chirp_id, matches, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: GetOpenModerationFlags :many
+SELECT * FROM moderation_flags
+WHERE resolved_at IS NULL
+ORDER BY created_at ASC;
+
+-- name: ResolveModerationFlag :execrows
+UPDATE moderation_flags
+SET resolved_at = $2
+WHERE id = $1 and resolved_at IS NULL;
\ No newline at end of file
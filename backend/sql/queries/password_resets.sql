@@ -0,0 +1,21 @@
+-- name: CreatePasswordReset :exec
+INSERT INTO password_resets(token_hash, user_id, expires_at, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: DeletePasswordResetsForUser :exec
+DELETE FROM password_resets
+WHERE user_id = $1;
+
+-- name: GetPasswordReset :one
+SELECT * FROM password_resets
+WHERE token_hash = $1;
+
+-- name: UsePasswordReset :execrows
+UPDATE password_resets
+SET used_at = $2
+WHERE token_hash = $1 AND used_at IS NULL;
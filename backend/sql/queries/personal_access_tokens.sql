@@ -0,0 +1,39 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens(id, user_id, name, token_hash, scopes, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+) RETURNING *;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT * FROM personal_access_tokens
+WHERE token_hash = $1
+  AND revoked_at IS NULL;
+
+-- name: GetPersonalAccessTokensForUser :many
+SELECT * FROM personal_access_tokens
+WHERE user_id = $1
+  AND revoked_at IS NULL
+ORDER BY created_at;
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_tokens
+SET last_used_at = $2
+WHERE id = $1;
+
+-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_tokens
+SET revoked_at = $1
+WHERE id = $2
+  AND user_id = $3
+  AND revoked_at IS NULL;
+
+-- name: RevokePersonalAccessTokensForUser :exec
+UPDATE personal_access_tokens
+SET revoked_at = $1
+WHERE user_id = $2
+  AND revoked_at IS NULL;
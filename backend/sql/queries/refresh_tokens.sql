@@ -1,20 +1,39 @@
 -- name: CreateRefreshTokenForUser :one
- INSERT INTO refresh_tokens(token, expires_at, revoked_at, created_at, updated_at, user_id)
+ INSERT INTO refresh_tokens(token_hash, expires_at, revoked_at, created_at, updated_at, user_id, family_id)
  VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7
  ) RETURNING *;
 
 -- name: GetTokenbyToken :one 
  SELECT * FROM refresh_tokens
- WHERE token = $1;
+ WHERE token_hash = $1;
 
--- name: RevokeToken :exec
+-- name: RevokeToken :execrows
 UPDATE refresh_tokens
 SET revoked_at = $2,
     updated_at = $3
-WHERE token = $1;
\ No newline at end of file
+WHERE token_hash = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET updated_at = $2,
+    revoked_at = $2
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET updated_at = $2,
+    revoked_at = $2
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeOtherRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET updated_at = $3,
+    revoked_at = $3
+WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,53 @@
+-- name: CreateSession :one
+INSERT INTO sessions(id, user_id, created_at, last_used_at, user_agent, ip_address)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+) RETURNING *;
+
+-- name: GetActiveSessionsForUser :many
+SELECT * FROM sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM refresh_tokens
+      WHERE refresh_tokens.family_id = sessions.id
+        AND refresh_tokens.revoked_at IS NULL
+        AND refresh_tokens.expires_at > sqlc.arg(now)
+  )
+ORDER BY last_used_at DESC;
+
+-- name: GetSessionsForUser :many
+SELECT * FROM sessions
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = $2,
+    user_agent = $3,
+    ip_address = $4
+WHERE id = $1;
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET revoked_at = sqlc.arg(revoked_at)::timestamp
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND revoked_at IS NULL;
+
+-- name: RevokeSessionsForUser :exec
+UPDATE sessions
+SET revoked_at = sqlc.arg(revoked_at)::timestamp
+WHERE user_id = sqlc.arg(user_id) AND revoked_at IS NULL;
+
+-- name: RevokeOtherSessionsForUser :exec
+UPDATE sessions
+SET revoked_at = sqlc.arg(revoked_at)::timestamp
+WHERE user_id = sqlc.arg(user_id) AND id <> sqlc.arg(keep_id) AND revoked_at IS NULL;
+
+-- name: DeleteSessionsForUser :exec
+DELETE FROM sessions
+WHERE user_id = $1;
@@ -1,21 +1,53 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, password)
+INSERT INTO users (id, created_at, updated_at, email, password, handle)
 VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6
 )
 RETURNING *;
 
--- name: UpdateCredentials :one
+-- name: UpdateUserEmail :one
 UPDATE users
 SET email = $2,
-    password = $3
+    updated_at = $3,
+    email_verified_at = $3
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateUserPassword :one
+UPDATE users
+SET password = $2,
+    updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: AnonymizeUser :exec
+UPDATE users
+SET email = $2,
+    password = '',
+    handle = $3,
+    display_name = '',
+    bio = '',
+    avatar_url = '',
+    is_chirpy_red = false,
+    updated_at = $4,
+    deleted_at = $4
+WHERE id = $1;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: SetEmailVerified :execrows
+UPDATE users
+SET updated_at = sqlc.arg(verified_at),
+    email_verified_at = sqlc.arg(verified_at)
+WHERE id = sqlc.arg(id) AND email = sqlc.arg(email);
+
 -- name: ResetUsers :exec
 DELETE FROM users;
 
@@ -23,7 +55,50 @@ DELETE FROM users;
 SELECT * FROM users
 WHERE email = $1;
 
+-- name: GetUserById :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUserByHandle :one
+SELECT * FROM users
+WHERE handle = $1;
+
+-- name: GetUsersByIds :many
+SELECT * FROM users
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: GetUsersByHandles :many
+SELECT id, handle FROM users
+WHERE handle = ANY(sqlc.arg(handles)::text[]);
+
+-- name: UpdateUserHandle :one
+UPDATE users
+SET handle = $2,
+    updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET display_name = $2,
+    bio = $3,
+    avatar_url = $4,
+    updated_at = $5
+WHERE id = $1
+RETURNING *;
+
 -- name: SetChirpyRedTrue :exec
 UPDATE users
 SET is_chirpy_red = true
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+-- name: EnsureDeletedUser :exec
+INSERT INTO users (id, created_at, updated_at, email, password, handle, deleted_at)
+VALUES (
+    $1,
+    $2,
+    $2,
+    $3,
+    '',
+    $4,
+    $2
+) ON CONFLICT DO NOTHING;
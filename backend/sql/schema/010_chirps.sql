@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN parent_id UUID NULL REFERENCES chirps (id) ON DELETE SET NULL,
+ADD COLUMN deleted_at TIMESTAMP NULL;
+
+CREATE INDEX chirps_parent_id_idx ON chirps (parent_id);
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN deleted_at,
+DROP COLUMN parent_id;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE
+    chirp_likes (
+        chirp_id UUID NOT NULL REFERENCES chirps (id) ON DELETE CASCADE,
+        user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        created_at TIMESTAMP NOT NULL,
+        PRIMARY KEY (chirp_id, user_id)
+    );
+
+-- +goose Down
+DROP TABLE chirp_likes;
\ No newline at end of file
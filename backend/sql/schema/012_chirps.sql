@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN repost_of UUID NULL REFERENCES chirps (id) ON DELETE CASCADE,
+ADD COLUMN quote_of UUID NULL REFERENCES chirps (id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX chirps_user_id_repost_of_idx ON chirps (user_id, repost_of)
+WHERE repost_of IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_user_id_repost_of_idx;
+
+ALTER TABLE chirps
+DROP COLUMN quote_of,
+DROP COLUMN repost_of;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE
+    chirp_revisions (
+        id UUID PRIMARY KEY,
+        chirp_id UUID NOT NULL REFERENCES chirps (id) ON DELETE CASCADE,
+        body TEXT NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        replaced_at TIMESTAMP NOT NULL
+    );
+
+CREATE INDEX chirp_revisions_chirp_id_idx ON chirp_revisions (chirp_id, replaced_at);
+
+-- +goose Down
+DROP TABLE chirp_revisions;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE
+    moderation_words (
+        word TEXT PRIMARY KEY,
+        created_at TIMESTAMP NOT NULL
+    );
+
+CREATE TABLE
+    moderation_flags (
+        id UUID PRIMARY KEY,
+        chirp_id UUID NOT NULL REFERENCES chirps (id) ON DELETE CASCADE,
+        matches TEXT[] NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        resolved_at TIMESTAMP NULL
+    );
+
+-- +goose Down
+DROP TABLE moderation_flags;
+
+DROP TABLE moderation_words;
\ No newline at end of file
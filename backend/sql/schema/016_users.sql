@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN handle TEXT;
+
+UPDATE users
+SET handle = 'user_' || LEFT(REPLACE(id::text, '-', ''), 10);
+
+ALTER TABLE users
+ALTER COLUMN handle SET NOT NULL;
+
+CREATE UNIQUE INDEX users_handle_idx ON users (handle);
+
+-- +goose Down
+DROP INDEX users_handle_idx;
+
+ALTER TABLE users
+DROP COLUMN handle;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN deleted_at TIMESTAMP NULL;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN deleted_at;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP NULL;
+
+UPDATE users
+SET email_verified_at = created_at;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN email_verified_at;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE
+    email_verifications (
+        id UUID PRIMARY KEY,
+        user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        email TEXT NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        used_at TIMESTAMP NULL
+    );
+
+CREATE INDEX email_verifications_user_id_idx ON email_verifications (user_id);
+
+-- +goose Down
+DROP TABLE email_verifications;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE
+    sessions (
+        id UUID PRIMARY KEY,
+        user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        created_at TIMESTAMP NOT NULL,
+        last_used_at TIMESTAMP NOT NULL,
+        user_agent TEXT NOT NULL,
+        ip_address TEXT NOT NULL,
+        revoked_at TIMESTAMP NULL
+    );
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id, last_used_at);
+
+INSERT INTO sessions (id, user_id, created_at, last_used_at, user_agent, ip_address, revoked_at)
+SELECT family_id,
+       user_id,
+       MIN(created_at),
+       MAX(updated_at),
+       '',
+       '',
+       CASE WHEN BOOL_AND(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id;
+
+ALTER TABLE refresh_tokens
+ADD CONSTRAINT refresh_tokens_family_id_fkey FOREIGN KEY (family_id) REFERENCES sessions (id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE refresh_tokens
+DROP CONSTRAINT refresh_tokens_family_id_fkey;
+
+DROP TABLE sessions;
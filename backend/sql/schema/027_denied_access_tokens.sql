@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE
+    denied_access_tokens (
+        jti TEXT PRIMARY KEY,
+        expires_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP NOT NULL
+    );
+
+CREATE INDEX denied_access_tokens_expires_at_idx ON denied_access_tokens (expires_at);
+
+-- +goose Down
+DROP TABLE denied_access_tokens;
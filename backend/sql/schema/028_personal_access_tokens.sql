@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE
+    personal_access_tokens (
+        id UUID PRIMARY KEY,
+        user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+        name TEXT NOT NULL,
+        token_hash TEXT NOT NULL UNIQUE,
+        scopes TEXT[] NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        last_used_at TIMESTAMP NULL,
+        revoked_at TIMESTAMP NULL
+    );
+
+CREATE INDEX personal_access_tokens_user_id_idx ON personal_access_tokens (user_id, created_at);
+
+-- +goose Down
+DROP TABLE personal_access_tokens;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- The row exists once an admin has set the word list, from then on the
+-- table is used even when it is empty.
+CREATE TABLE
+    moderation_word_list (
+        id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+        updated_at TIMESTAMP NOT NULL
+    );
+
+INSERT INTO moderation_word_list (updated_at)
+SELECT MAX(created_at) FROM moderation_words
+HAVING COUNT(*) > 0;
+
+-- +goose Down
+DROP TABLE moderation_word_list;